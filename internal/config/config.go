@@ -4,15 +4,20 @@ import (
 	"encoding/json"
 	"os"
 	"sync"
-	"time"
+
+	"github.com/fuxingjun/balance-bot/pkg"
 )
 
 type TokenConfig struct {
-	Address string  `json:"address"`
-	ChainId string  `json:"chainId,omitempty"` // 允许为空, 默认 56
-	Name    string  `json:"name,omitempty"`    // 允许为空, 默认取地址后四位
-	Min     float64 `json:"min,omitempty"`     // 允许为空, 默认 0.1
-	Max     float64 `json:"max,omitempty"`     // 允许为空, 默认不限
+	Address string      `json:"address"`
+	ChainId string      `json:"chainId,omitempty"` // 允许为空, 默认 56
+	Name    string      `json:"name,omitempty"`    // 允许为空, 默认取地址后四位
+	Min     json.Number `json:"min,omitempty"`     // 允许为空, 默认 0.1, 保留原始文本以精确比较
+	Max     json.Number `json:"max,omitempty"`     // 允许为空, 默认不限
+	// ERC20 代币合约地址, 允许为空, 为空时查询原生代币余额
+	Contract string `json:"contract,omitempty"`
+	// ERC20 代币精度, 允许为空, 为空时自动通过合约 decimals() 获取
+	Decimals *uint8 `json:"decimals,omitempty"`
 }
 
 type WebhookConfig struct {
@@ -23,8 +28,87 @@ type WebhookConfig struct {
 }
 
 type HealthCheckConfig struct {
-	Interval  int `json:"interval,omitempty"`  // 允许为空, 默认 10s
-	WarnCount int `json:"warnCount,omitempty"` // 警告次数 允许为空, 默认 3 次
+	Interval  int                            `json:"interval,omitempty"`  // 允许为空, 默认 10s
+	WarnCount int                            `json:"warnCount,omitempty"` // 警告次数 允许为空, 默认 3 次
+	Strict    bool                           `json:"strict,omitempty"`    // 严格模式, 只接受 services 中配置的服务
+	Services  map[string]HealthServiceConfig `json:"services,omitempty"`  // 按服务名称配置的心跳策略
+}
+
+// 单个服务的心跳策略, 也可以由服务在 /health 请求中声明
+type HealthServiceConfig struct {
+	Interval  int      `json:"interval,omitempty"`  // 心跳间隔(秒), 允许为空, 默认取 healthCheck.interval
+	Grace     int      `json:"grace,omitempty"`     // 超时宽限(秒), 超过 interval + grace 未收到心跳才告警, 允许为空, 默认 0
+	WarnCount int      `json:"warnCount,omitempty"` // 警告次数, 允许为空, 默认取 healthCheck.warnCount
+	Channels  []string `json:"channels,omitempty"`  // 通知渠道 telegram / wecom / lark, 允许为空, 默认全部
+	// 对心跳 extra 内容的断言, 如 "$.queueDepth < 1000", 任一不满足即告警; 只能在配置中设置
+	Rules []string `json:"rules,omitempty"`
+}
+
+// 超过多少秒未收到心跳视为超时
+func (s *HealthServiceConfig) Timeout() int {
+	return s.Interval + s.Grace
+}
+
+// 服务是否在 services 中配置
+func (c *HealthCheckConfig) Known(name string) bool {
+	_, exists := c.Services[name]
+	return exists
+}
+
+// 计算服务生效的心跳策略, 优先级: services 配置 > 服务声明 > 全局配置, rules 只取 services 配置
+func (c *HealthCheckConfig) Policy(name string, declared HealthServiceConfig) HealthServiceConfig {
+	policy := HealthServiceConfig{
+		Interval:  c.Interval,
+		WarnCount: c.WarnCount,
+	}
+	for _, source := range []HealthServiceConfig{declared, c.Services[name]} {
+		if source.Interval > 0 {
+			policy.Interval = source.Interval
+		}
+		if source.Grace > 0 {
+			policy.Grace = source.Grace
+		}
+		if source.WarnCount > 0 {
+			policy.WarnCount = source.WarnCount
+		}
+		if len(source.Channels) > 0 {
+			policy.Channels = source.Channels
+		}
+	}
+	policy.Rules = c.Services[name].Rules
+	return policy
+}
+
+type AlertConfig struct {
+	RenotifyInterval int `json:"renotifyInterval,omitempty"` // 同一告警重复通知的最小间隔(秒), 允许为空, 默认 3600
+}
+
+type StateConfig struct {
+	Disabled bool   `json:"disabled,omitempty"` // 是否禁用状态持久化
+	Dir      string `json:"dir,omitempty"`      // 状态存储目录, 允许为空, 默认 data
+}
+
+type APIToken struct {
+	Name   string   `json:"name,omitempty"`   // token 名称, 用于日志
+	Token  string   `json:"token"`            // Bearer token
+	Scopes []string `json:"scopes,omitempty"` // 允许的权限: health / monitor / status, 为空或包含 * 表示全部
+}
+
+type HMACConfig struct {
+	Secret  string `json:"secret,omitempty"`  // 签名密钥, 为空表示不启用签名校验
+	MaxSkew int    `json:"maxSkew,omitempty"` // 时间戳允许的最大偏差(秒), 允许为空, 默认 300
+}
+
+// ExchangeAPIKey 交易所 API 密钥, 只用于查询需要签名的合约信息, 建议使用只读权限的密钥
+type ExchangeAPIKey struct {
+	Key    string `json:"key"`
+	Secret string `json:"secret"`
+}
+
+type APIConfig struct {
+	Tokens      []APIToken `json:"tokens,omitempty"`      // 为空表示不启用 token 鉴权
+	HMAC        HMACConfig `json:"hmac"`                  // 写接口请求签名
+	CORSOrigins []string   `json:"corsOrigins,omitempty"` // 允许跨域的来源, 允许为空, 默认 https://taoli.tools
 }
 
 type VolumeMonitorConfig struct {
@@ -37,24 +121,204 @@ type VolumeMonitorPlatform struct {
 	ThresholdUSD float64 `json:"thresholdUSD,omitempty"` // 24h交易量阈值，单位美元，小于该值告警 默认50w
 }
 
+type OpenInterestMonitorConfig struct {
+	Enabled       bool                          `json:"enabled,omitempty"`       // 是否启用持仓量监控
+	Interval      int                           `json:"interval,omitempty"`      // 检查间隔, 单位秒, 允许为空, 默认 60 秒
+	Window        int                           `json:"window,omitempty"`        // 跌幅统计的滚动窗口, 单位秒, 允许为空, 默认 3600 秒
+	DropThreshold float64                       `json:"dropThreshold,omitempty"` // 窗口内相对最高值的跌幅阈值, 0.3 表示 30%, 超过告警, 允许为空, 默认 0.3
+	NotifyCount   int                           `json:"notifyCount,omitempty"`   // 24h 内同一告警的通知次数, 允许为空, 默认 3 次
+	Platform      []OpenInterestMonitorPlatform `json:"platform,omitempty"`      // 交易所阈值, 未配置的交易所使用默认阈值
+}
+
+type OpenInterestMonitorPlatform struct {
+	Platform     string  `json:"platform"`               // 交易所
+	ThresholdUSD float64 `json:"thresholdUSD,omitempty"` // 持仓量阈值, 单位美元, 小于该值告警, 允许为空
+}
+
+type FundingMonitorConfig struct {
+	NotifyCount int                      `json:"notifyCount,omitempty"` // 24h 内同一告警的通知次数, 允许为空, 默认 3 次
+	Platform    []FundingMonitorPlatform `json:"platform,omitempty"`    // 交易所阈值, 未配置的交易所使用默认阈值
+}
+
+type FundingMonitorPlatform struct {
+	Platform           string  `json:"platform"`                     // 交易所
+	Threshold          float64 `json:"threshold,omitempty"`          // 当前资金费率绝对值阈值, 0.005 表示 0.5%, 超过告警, 允许为空, 默认 0.005
+	PredictedThreshold float64 `json:"predictedThreshold,omitempty"` // 预测资金费率绝对值阈值, 允许为空, 默认同 threshold
+}
+
+type SpreadMonitorConfig struct {
+	Enabled       bool    `json:"enabled,omitempty"`       // 是否启用交易对两腿价差监控
+	Interval      int     `json:"interval,omitempty"`      // 检查间隔, 单位秒, 允许为空, 默认 10 秒
+	Threshold     float64 `json:"threshold,omitempty"`     // 价差绝对值阈值, 0.01 表示 1%, 超过告警, 允许为空, 默认 0.01
+	FlipMinSpread float64 `json:"flipMinSpread,omitempty"` // 价差反向告警的最小价差绝对值, 低于该值视为噪声, 允许为空, 默认 0.001
+	PriceType     string  `json:"priceType,omitempty"`     // 使用的价格, last 或 mark, 允许为空, 默认 mark
+	NotifyCount   int     `json:"notifyCount,omitempty"`   // 24h 内同一告警的通知次数, 允许为空, 默认 3 次
+}
+
+// 最小值阈值
+func (t *TokenConfig) MinAmount() pkg.Amount {
+	minAmount, err := pkg.ParseAmount(string(t.Min))
+	if err != nil {
+		return pkg.Amount{}
+	}
+	return minAmount
+}
+
+// 最大值阈值, 未配置或不大于 0 时返回 false 表示不限
+func (t *TokenConfig) MaxAmount() (pkg.Amount, bool) {
+	if t.Max == "" {
+		return pkg.Amount{}, false
+	}
+	maxAmount, err := pkg.ParseAmount(string(t.Max))
+	if err != nil || maxAmount.Sign() <= 0 {
+		return pkg.Amount{}, false
+	}
+	return maxAmount, true
+}
+
+// 链配置, 以 chainId 作为 key
+type ChainConfig struct {
+	Name     string   `json:"name,omitempty"`     // 链名称, 用于展示
+	RPC      []string `json:"rpc,omitempty"`      // RPC 地址列表, 轮询使用
+	Symbol   string   `json:"symbol,omitempty"`   // 原生代币符号
+	Decimals uint8    `json:"decimals,omitempty"` // 原生代币精度, 默认 18
+	// 单次 JSON-RPC 批量请求包含的调用数量, 默认 50
+	BatchSize int `json:"batchSize,omitempty"`
+}
+
+// 内置常用链配置, 用户配置的 chains 会按字段覆盖
+var DefaultChains = map[string]ChainConfig{
+	"1": {
+		Name:     "Ethereum",
+		RPC:      []string{"https://ethereum-rpc.publicnode.com", "https://eth.llamarpc.com", "https://1rpc.io/eth"},
+		Symbol:   "ETH",
+		Decimals: 18,
+	},
+	"10": {
+		Name:     "Optimism",
+		RPC:      []string{"https://mainnet.optimism.io", "https://optimism-rpc.publicnode.com"},
+		Symbol:   "ETH",
+		Decimals: 18,
+	},
+	"56": {
+		Name: "BSC",
+		RPC: []string{
+			"https://bsc-dataseed.bnbchain.org",
+			"https://bsc-dataseed.nariox.org",
+			"https://bsc-dataseed.defibit.io",
+			"https://bsc-dataseed.ninicoin.io",
+			"https://bsc.nodereal.io",
+		},
+		Symbol:   "BNB",
+		Decimals: 18,
+	},
+	"137": {
+		Name:     "Polygon",
+		RPC:      []string{"https://polygon-rpc.com", "https://polygon-bor-rpc.publicnode.com"},
+		Symbol:   "POL",
+		Decimals: 18,
+	},
+	"8453": {
+		Name:     "Base",
+		RPC:      []string{"https://mainnet.base.org", "https://base-rpc.publicnode.com"},
+		Symbol:   "ETH",
+		Decimals: 18,
+	},
+	"42161": {
+		Name:     "Arbitrum",
+		RPC:      []string{"https://arb1.arbitrum.io/rpc", "https://arbitrum-one-rpc.publicnode.com"},
+		Symbol:   "ETH",
+		Decimals: 18,
+	},
+}
+
 type AppConfig struct {
-	Webhook               WebhookConfig       `json:"webhook"`
-	Interval              int                 `json:"interval,omitempty"` // 允许为空, 默认 30s
-	Tokens                []TokenConfig       `json:"tokens"`
-	HealthCheck           HealthCheckConfig   `json:"healthCheck"`
-	VolumeMonitor         VolumeMonitorConfig `json:"volumeMonitor"`                   // 交易量监控配置
-	IndexComponentMonitor bool                `json:"indexComponentMonitor,omitempty"` // 是否启用合约指数成份监控
+	Webhook               WebhookConfig             `json:"webhook"`
+	Interval              int                       `json:"interval,omitempty"` // 允许为空, 默认 30s
+	Tokens                []TokenConfig             `json:"tokens"`
+	HealthCheck           HealthCheckConfig         `json:"healthCheck"`
+	VolumeMonitor         VolumeMonitorConfig       `json:"volumeMonitor"`                   // 交易量监控配置
+	FundingMonitor        FundingMonitorConfig      `json:"fundingMonitor"`                  // 资金费率监控配置
+	SpreadMonitor         SpreadMonitorConfig       `json:"spreadMonitor"`                   // 两腿价差监控配置
+	OpenInterestMonitor   OpenInterestMonitorConfig `json:"openInterestMonitor"`             // 持仓量监控配置
+	IndexComponentMonitor bool                      `json:"indexComponentMonitor,omitempty"` // 是否启用合约指数成份监控
+	ContractStatusMonitor bool                      `json:"contractStatusMonitor,omitempty"` // 是否启用合约状态监控(下架、交割、杠杆与持仓上限变化)
+	ExchangeAPI           map[string]ExchangeAPIKey `json:"exchangeApi,omitempty"`           // 交易所 API 密钥, key 为交易所名称, 目前只有 binance 用于查询杠杆分层
+	Chains                map[string]ChainConfig    `json:"chains,omitempty"`                // 链配置, 与内置链合并
+	Alert                 AlertConfig               `json:"alert"`                           // 告警去重配置
+	State                 StateConfig               `json:"state"`                           // 状态持久化配置
+	API                   APIConfig                 `json:"api"`                             // HTTP 接口鉴权与跨域配置
+	Maintenance           []MaintenanceWindow       `json:"maintenance,omitempty"`           // 维护窗口, 窗口内不发送告警
+}
+
+// 获取链配置
+func (c *AppConfig) GetChain(chainId string) (ChainConfig, bool) {
+	chain, exists := c.Chains[chainId]
+	return chain, exists
+}
+
+// 合并内置链与用户配置的链, 用户配置的非空字段优先
+func mergeChains(custom map[string]ChainConfig) map[string]ChainConfig {
+	merged := make(map[string]ChainConfig, len(DefaultChains)+len(custom))
+	for id, chain := range DefaultChains {
+		merged[id] = chain
+	}
+	for id, chain := range custom {
+		base := merged[id]
+		if chain.Name != "" {
+			base.Name = chain.Name
+		}
+		if len(chain.RPC) > 0 {
+			base.RPC = chain.RPC
+		}
+		if chain.Symbol != "" {
+			base.Symbol = chain.Symbol
+		}
+		if chain.Decimals > 0 {
+			base.Decimals = chain.Decimals
+		}
+		if chain.BatchSize > 0 {
+			base.BatchSize = chain.BatchSize
+		}
+		merged[id] = base
+	}
+	for id, chain := range merged {
+		if chain.Decimals == 0 {
+			chain.Decimals = 18 // 默认 18 位精度
+		}
+		if chain.BatchSize <= 0 {
+			chain.BatchSize = 50 // 默认每批 50 个调用
+		}
+		merged[id] = chain
+	}
+	return merged
 }
 
-// 缓存config, 5秒刷新一次
+// 当前生效的配置, 首次读取后由 Watch 负责在文件变更时重新加载
 var configCache *AppConfig
 var configMutex sync.RWMutex
-var lastLoadTime int64
 
-// 读取 config.json 文件
+// 配置文件路径, 可通过 -config 参数指定
+var configPath = "config.json"
+
+// SetPath 设置配置文件路径, 需在首次 LoadConfig 之前调用
+func SetPath(path string) {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+	configPath = path
+}
+
+// Path 当前使用的配置文件路径
+func Path() string {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+	return configPath
+}
+
+// 读取配置文件, 首次调用时从文件加载, 之后返回当前生效的配置
 func LoadConfig() (*AppConfig, error) {
 	configMutex.RLock()
-	if configCache != nil && (time.Now().Unix()-lastLoadTime) < 5 {
+	if configCache != nil {
 		defer configMutex.RUnlock()
 		return configCache, nil
 	}
@@ -64,30 +328,63 @@ func LoadConfig() (*AppConfig, error) {
 	configMutex.Lock()
 	defer configMutex.Unlock()
 
-	// 双重检查，避免其他 goroutine 已经更新了配置
-	if configCache != nil && (time.Now().Unix()-lastLoadTime) < 5 {
+	// 双重检查，避免其他 goroutine 已经加载了配置
+	if configCache != nil {
 		return configCache, nil
 	}
 
-	if _, err := os.Stat("config.json"); os.IsNotExist(err) {
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		// 文件不存在，写入示例文件
-		err := WriteConfig()
+		err := writeSampleConfig(configPath)
 		if err != nil {
 			return nil, err
 		}
 		return nil, nil
 	}
-	// 文件存在，读取并解析配置
-	data, err := os.ReadFile("config.json")
+	config, files, err := readConfigFile(configPath)
 	if err != nil {
 		return nil, err
 	}
+	configCache = config
+	rememberFileState(statFiles(files))
+
+	return config, nil
+}
+
+// 读取并解析配置文件, 支持 JSON / YAML / TOML, 同时返回 file: 引用的文件
+func readConfigFile(path string) (*AppConfig, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, files, err := toJSON(path, data)
+	if err != nil {
+		return nil, files, err
+	}
+	config, err := ParseConfig(data)
+	return config, files, err
+}
+
+// ParseConfig 解析配置内容, 填充默认值并校验, 校验失败时返回包含所有错误的 ValidationErrors
+func ParseConfig(data []byte) (*AppConfig, error) {
 	var config AppConfig
 	// 解析 JSON 数据
-	err = json.Unmarshal(data, &config)
+	err := json.Unmarshal(data, &config)
 	if err != nil {
-		return nil, err
+		return nil, describeJSONError(data, err)
 	}
+	// 未知字段通常是拼写错误, 与其它校验错误一起报告
+	errs := checkUnknownFields(data)
+	config.applyDefaults()
+	errs = append(errs, config.validate()...)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &config, nil
+}
+
+// 填充默认值
+func (config *AppConfig) applyDefaults() {
 	if config.Interval == 0 {
 		config.Interval = 30 // 默认 30 秒
 	}
@@ -97,26 +394,81 @@ func LoadConfig() (*AppConfig, error) {
 	if config.HealthCheck.WarnCount == 0 {
 		config.HealthCheck.WarnCount = 3 // 默认 3 次
 	}
+	if config.VolumeMonitor.NotifyCount == 0 {
+		config.VolumeMonitor.NotifyCount = 3 // 默认 3 次
+	}
+	if config.FundingMonitor.NotifyCount == 0 {
+		config.FundingMonitor.NotifyCount = 3 // 默认 3 次
+	}
+	if config.OpenInterestMonitor.Interval == 0 {
+		config.OpenInterestMonitor.Interval = 60 // 默认 60 秒
+	}
+	if config.OpenInterestMonitor.Window == 0 {
+		config.OpenInterestMonitor.Window = 3600 // 默认 1 小时
+	}
+	if config.OpenInterestMonitor.DropThreshold == 0 {
+		config.OpenInterestMonitor.DropThreshold = 0.3 // 默认 30%
+	}
+	if config.OpenInterestMonitor.NotifyCount == 0 {
+		config.OpenInterestMonitor.NotifyCount = 3 // 默认 3 次
+	}
+	if config.SpreadMonitor.Interval == 0 {
+		config.SpreadMonitor.Interval = 10 // 默认 10 秒
+	}
+	if config.SpreadMonitor.Threshold == 0 {
+		config.SpreadMonitor.Threshold = 0.01 // 默认 1%
+	}
+	if config.SpreadMonitor.FlipMinSpread == 0 {
+		config.SpreadMonitor.FlipMinSpread = 0.001 // 默认 0.1%
+	}
+	if config.SpreadMonitor.PriceType == "" {
+		config.SpreadMonitor.PriceType = "mark"
+	}
+	if config.SpreadMonitor.NotifyCount == 0 {
+		config.SpreadMonitor.NotifyCount = 3 // 默认 3 次
+	}
+	if config.Alert.RenotifyInterval == 0 {
+		config.Alert.RenotifyInterval = 3600 // 默认 1 小时
+	}
+	if config.State.Dir == "" {
+		config.State.Dir = "data" // 默认 data 目录
+	}
+	if config.API.HMAC.MaxSkew == 0 {
+		config.API.HMAC.MaxSkew = 300 // 默认 5 分钟
+	}
+	if len(config.API.CORSOrigins) == 0 {
+		config.API.CORSOrigins = []string{"https://taoli.tools"}
+	}
+
+	// 合并链配置
+	config.Chains = mergeChains(config.Chains)
 
 	// 设置Token默认值
 	for i := range config.Tokens {
 		if config.Tokens[i].ChainId == "" {
 			config.Tokens[i].ChainId = "56" // 默认BSC链
 		}
-		if config.Tokens[i].Min <= 0 {
-			config.Tokens[i].Min = 0.1 // 默认最小值
+		minAmount, err := pkg.ParseAmount(string(config.Tokens[i].Min))
+		if config.Tokens[i].Min == "" || (err == nil && minAmount.Sign() <= 0) {
+			config.Tokens[i].Min = "0.1" // 默认最小值
 		}
 	}
+}
 
-	configCache = &config
-	lastLoadTime = time.Now().Unix()
-
-	return &config, nil
+// 按扩展名对应的格式写入示例文件
+func writeSampleConfig(path string) error {
+	data, err := sampleConfig(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
 }
 
-// 写入 config.json 示例文件
-func WriteConfig() error {
-	configStr := `{
+// 示例配置中的占位 token, 校验时拒绝, 避免原样部署后使用可猜测的 token
+const placeholderToken = "change-me"
+
+// 示例配置
+const sampleConfigJSON = `{
   "webhook": {
     "wecom": "",
     "lark": "",
@@ -124,6 +476,19 @@ func WriteConfig() error {
     "telegram_chat_id": ""
   },
   "interval": 30,
+  "alert": {
+    "renotifyInterval": 3600
+  },
+  "api": {
+    "tokens": [
+      {
+        "name": "taoli-tools",
+        "token": "change-me",
+        "scopes": ["health", "monitor", "status"]
+      }
+    ],
+    "corsOrigins": ["https://taoli.tools"]
+  },
   "tokens": [
     {
       "address": "0x1234567890abcdef1234567890abcdef12345678",
@@ -133,16 +498,24 @@ func WriteConfig() error {
       "max": 1000
     },
     {
-      "address": "0x1234567890abcdef1234567890abcdef12345678",
+      "address": "0xabcdef1234567890abcdef1234567890abcdef12",
       "chainId": "56",
-      "name": "MyWallet01",
+      "name": "MyWallet02",
       "min": 0.1,
       "max": 1000
+    },
+    {
+      "address": "0x1234567890abcdef1234567890abcdef12345678",
+      "chainId": "56",
+      "name": "MyWallet01-USDT",
+      "contract": "0x55d398326f99059fF775485246999027B3197955",
+      "min": 100,
+      "max": 100000
     }
   ],
   "volumeMonitor": {
-		"notifyCount": 3,
-		"platform": [
+    "notifyCount": 3,
+    "platform": [
       {
         "platform": "gate",
         "thresholdUSD": 500000
@@ -152,8 +525,44 @@ func WriteConfig() error {
         "thresholdUSD": 5000000
       }
     ]
-	},
-	"indexComponentMonitor": true,
+  },
+  "fundingMonitor": {
+    "notifyCount": 3,
+    "platform": [
+      {
+        "platform": "gate",
+        "threshold": 0.005,
+        "predictedThreshold": 0.005
+      },
+      {
+        "platform": "binance",
+        "threshold": 0.005
+      }
+    ]
+  },
+  "openInterestMonitor": {
+    "enabled": true,
+    "interval": 60,
+    "window": 3600,
+    "dropThreshold": 0.3,
+    "platform": [
+      {
+        "platform": "gate",
+        "thresholdUSD": 200000
+      },
+      {
+        "platform": "binance",
+        "thresholdUSD": 2000000
+      }
+    ]
+  },
+  "spreadMonitor": {
+    "enabled": true,
+    "interval": 10,
+    "threshold": 0.01,
+    "flipMinSpread": 0.001,
+    "priceType": "mark"
+  },
+  "indexComponentMonitor": true,
+  "contractStatusMonitor": true
 }`
-	return os.WriteFile("config.json", []byte(configStr), 0644)
-}
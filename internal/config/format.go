@@ -0,0 +1,319 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// --- 多格式配置文件与密钥引用 ---
+
+// 配置文件格式, 按扩展名区分, 其它扩展名按 JSON 处理
+const (
+	formatJSON = "json"
+	formatYAML = "yaml"
+	formatTOML = "toml"
+)
+
+func configFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return formatYAML
+	case ".toml":
+		return formatTOML
+	default:
+		return formatJSON
+	}
+}
+
+// 字符串中的环境变量引用, 如 ${TELEGRAM_TOKEN}
+var envRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// 文件引用前缀, 如 file:/run/secrets/wecom, 相对路径相对于配置文件所在目录
+const fileRefPrefix = "file:"
+
+// 将配置文件内容统一转换为 JSON, 并展开字符串中的环境变量与文件引用
+// 同时返回 file: 引用的文件, 热加载时一并监听
+func toJSON(path string, data []byte) ([]byte, []string, error) {
+	var tree any
+	var errs ValidationErrors
+	switch configFormat(path) {
+	case formatYAML:
+		// 经 yaml.Node 解析以保留数字原文, 避免金额阈值经 float64 丢失精度
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return nil, nil, ValidationErrors{{Message: fmt.Sprintf("invalid YAML: %v", err)}}
+		}
+		var err error
+		if tree, err = yamlValue(&node); err != nil {
+			return nil, nil, ValidationErrors{{Message: fmt.Sprintf("invalid YAML: %v", err)}}
+		}
+	case formatTOML:
+		var table map[string]any
+		if _, err := toml.Decode(string(data), &table); err != nil {
+			return nil, nil, ValidationErrors{{Message: fmt.Sprintf("invalid TOML: %v", err)}}
+		}
+		// TOML 解析器不保留数字原文, 金额阈值必须写成字符串
+		checkTOMLAmounts(table, &errs)
+		tree = table
+	default:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&tree); err != nil {
+			return nil, nil, describeJSONError(data, err)
+		}
+	}
+
+	var files []string
+	tree, changed := expandRefs("", tree, filepath.Dir(path), &errs, &files)
+	if len(errs) > 0 {
+		sort.SliceStable(errs, func(i, j int) bool { return errs[i].Path < errs[j].Path })
+		return nil, files, errs
+	}
+	// JSON 没有引用时直接使用原文, 保留错误提示中的行列号
+	if configFormat(path) == formatJSON && !changed {
+		return data, files, nil
+	}
+	data, err := json.Marshal(tree)
+	return data, files, err
+}
+
+// 将 YAML 节点转换为通用结构, 整数与浮点数保留原文为 json.Number
+// 原文不是合法 JSON 数字时(如 0x10、1_000、.inf)按 YAML 规则解析
+func yamlValue(node *yaml.Node) (any, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return yamlValue(node.Content[0])
+	case yaml.AliasNode:
+		return yamlValue(node.Alias)
+	case yaml.MappingNode:
+		table := make(map[string]any, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, item := node.Content[i], node.Content[i+1]
+			value, err := yamlValue(item)
+			if err != nil {
+				return nil, err
+			}
+			// 合并键 <<: *anchor, 已显式设置的字段优先
+			if key.Tag == "!!merge" {
+				merged, err := yamlMerge(value)
+				if err != nil {
+					return nil, err
+				}
+				for k, v := range merged {
+					if _, exists := table[k]; !exists {
+						table[k] = v
+					}
+				}
+				continue
+			}
+			table[key.Value] = value
+		}
+		return table, nil
+	case yaml.SequenceNode:
+		list := make([]any, 0, len(node.Content))
+		for _, item := range node.Content {
+			value, err := yamlValue(item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		return list, nil
+	}
+	if tag := node.ShortTag(); (tag == "!!int" || tag == "!!float") && json.Valid([]byte(node.Value)) {
+		return json.Number(node.Value), nil
+	}
+	var value any
+	if err := node.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// 合并键的值可以是一个映射或映射列表, 列表中靠前的优先
+func yamlMerge(value any) (map[string]any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		return v, nil
+	case []any:
+		merged := make(map[string]any)
+		for _, item := range v {
+			table, ok := item.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("merge value must be a mapping")
+			}
+			for k, field := range table {
+				if _, exists := merged[k]; !exists {
+					merged[k] = field
+				}
+			}
+		}
+		return merged, nil
+	}
+	return nil, fmt.Errorf("merge value must be a mapping")
+}
+
+// 金额阈值按原文精确比较, TOML 中未加引号的小数会先转换为 float64, 直接报错
+func checkTOMLAmounts(table map[string]any, errs *ValidationErrors) {
+	var tokens []map[string]any
+	switch v := table["tokens"].(type) {
+	case []map[string]any:
+		tokens = v
+	case []any:
+		for _, item := range v {
+			token, _ := item.(map[string]any)
+			tokens = append(tokens, token)
+		}
+	}
+	for i, token := range tokens {
+		for _, field := range []string{"min", "max"} {
+			if _, ok := token[field].(float64); ok {
+				*errs = append(*errs, ValidationError{
+					Path:    fmt.Sprintf("tokens[%d].%s", i, field),
+					Message: fmt.Sprintf(`decimal amounts must be quoted in TOML to keep full precision, e.g. %s = "0.1"`, field),
+				})
+			}
+		}
+	}
+}
+
+// 递归展开引用, 返回新值以及是否发生了替换, 引用的文件记录到 files
+func expandRefs(path string, value any, baseDir string, errs *ValidationErrors, files *[]string) (any, bool) {
+	switch v := value.(type) {
+	case string:
+		return expandString(path, v, baseDir, errs, files)
+	case map[string]any:
+		changed := false
+		for key, item := range v {
+			var itemChanged bool
+			v[key], itemChanged = expandRefs(joinPath(path, key), item, baseDir, errs, files)
+			changed = changed || itemChanged
+		}
+		return v, changed
+	case map[any]any:
+		// YAML 中未加引号的数字 key (如 chains 下的 56) 会解析为非字符串 key
+		table := make(map[string]any, len(v))
+		for key, item := range v {
+			table[fmt.Sprint(key)] = item
+		}
+		expanded, _ := expandRefs(path, table, baseDir, errs, files)
+		return expanded, true
+	case []any:
+		changed := false
+		for i, item := range v {
+			var itemChanged bool
+			v[i], itemChanged = expandRefs(path+"["+strconv.Itoa(i)+"]", item, baseDir, errs, files)
+			changed = changed || itemChanged
+		}
+		return v, changed
+	}
+	return value, false
+}
+
+func expandString(path, value, baseDir string, errs *ValidationErrors, files *[]string) (string, bool) {
+	if name, ok := strings.CutPrefix(value, fileRefPrefix); ok {
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(baseDir, name)
+		}
+		*files = append(*files, name)
+		content, err := os.ReadFile(name)
+		if err != nil {
+			*errs = append(*errs, ValidationError{Path: path, Message: fmt.Sprintf("failed to read referenced file: %v", err)})
+			return value, false
+		}
+		// 密钥文件通常以换行结尾
+		return strings.TrimRight(string(content), "\r\n"), true
+	}
+	if !strings.Contains(value, "${") {
+		return value, false
+	}
+	var missing []string
+	expanded := envRefPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		name := envRefPattern.FindStringSubmatch(ref)[1]
+		env, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return env
+	})
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		*errs = append(*errs, ValidationError{Path: path, Message: "environment variable not set: " + strings.Join(missing, ", ")})
+		return value, false
+	}
+	return expanded, expanded != value
+}
+
+// 按配置文件格式生成示例内容, 示例统一以 JSON 维护
+func sampleConfig(path string) ([]byte, error) {
+	format := configFormat(path)
+	if format == formatJSON {
+		return []byte(sampleConfigJSON), nil
+	}
+	decoder := json.NewDecoder(strings.NewReader(sampleConfigJSON))
+	decoder.UseNumber()
+	var tree any
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+	// TOML 中金额阈值写成字符串, 见 checkTOMLAmounts
+	if format == formatTOML {
+		quoteAmounts(tree)
+	}
+	// 整数保持整数, 避免 TOML 中输出 30.0
+	tree = normalizeNumbers(tree)
+	if format == formatYAML {
+		return yaml.Marshal(tree)
+	}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(tree); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func quoteAmounts(tree any) {
+	table, _ := tree.(map[string]any)
+	tokens, _ := table["tokens"].([]any)
+	for _, item := range tokens {
+		token, _ := item.(map[string]any)
+		for _, field := range []string{"min", "max"} {
+			if number, ok := token[field].(json.Number); ok {
+				token[field] = number.String()
+			}
+		}
+	}
+}
+
+func normalizeNumbers(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalizeNumbers(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = normalizeNumbers(item)
+		}
+	}
+	return value
+}
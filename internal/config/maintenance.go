@@ -0,0 +1,130 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/fuxingjun/balance-bot/pkg"
+)
+
+// MaintenanceWindow 维护窗口, 窗口内匹配的服务与地址不发送告警
+type MaintenanceWindow struct {
+	Name     string   `json:"name"`               // 窗口名称, 用于展示
+	Cron     string   `json:"cron,omitempty"`     // 周期窗口的开始时间, 5 段 cron 表达式: 分 时 日 月 周
+	Start    string   `json:"start,omitempty"`    // 一次性窗口的开始时间, RFC3339, 与 cron 二选一
+	Duration int      `json:"duration"`           // 窗口时长(秒)
+	Timezone string   `json:"timezone,omitempty"` // cron 使用的时区, 如 Asia/Shanghai, 允许为空, 默认本地时区
+	Targets  []string `json:"targets,omitempty"`  // 匹配的服务名称、监控地址或名称, 支持 * 通配, 为空表示全部
+}
+
+// Matches 判断目标是否在窗口范围内, 地址不区分大小写
+func (w *MaintenanceWindow) Matches(targets ...string) bool {
+	if len(w.Targets) == 0 {
+		return true
+	}
+	for _, pattern := range w.Targets {
+		for _, target := range targets {
+			if target == "" {
+				continue
+			}
+			if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(target)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ActiveAt 判断 t 时刻窗口是否生效, 生效时返回窗口结束时间
+func (w *MaintenanceWindow) ActiveAt(t time.Time) (time.Time, bool) {
+	duration := time.Duration(w.Duration) * time.Second
+	if w.Start != "" {
+		start, err := time.Parse(time.RFC3339, w.Start)
+		if err != nil {
+			return time.Time{}, false
+		}
+		end := start.Add(duration)
+		return end, !t.Before(start) && t.Before(end)
+	}
+	schedule, loc, err := w.schedule()
+	if err != nil {
+		return time.Time{}, false
+	}
+	// 窗口 [s, s+duration) 覆盖 t, 等价于 (t-duration, t] 内存在开始时间
+	start := schedule.Next(t.In(loc).Add(-duration))
+	if start.IsZero() || start.After(t) {
+		return time.Time{}, false
+	}
+	// 多个窗口重叠时取最后开始的窗口
+	for next := schedule.Next(start); !next.IsZero() && !next.After(t); next = schedule.Next(next) {
+		start = next
+	}
+	return start.Add(duration), true
+}
+
+// NextStart 返回 t 之后下一次窗口开始时间, 不会再开始时返回 false
+func (w *MaintenanceWindow) NextStart(t time.Time) (time.Time, bool) {
+	if w.Start != "" {
+		start, err := time.Parse(time.RFC3339, w.Start)
+		return start, err == nil && start.After(t)
+	}
+	schedule, loc, err := w.schedule()
+	if err != nil {
+		return time.Time{}, false
+	}
+	next := schedule.Next(t.In(loc))
+	return next, !next.IsZero()
+}
+
+func (w *MaintenanceWindow) schedule() (*pkg.CronSchedule, *time.Location, error) {
+	loc := time.Local
+	if w.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(w.Timezone); err != nil {
+			return nil, nil, err
+		}
+	}
+	schedule, err := pkg.ParseCron(w.Cron)
+	return schedule, loc, err
+}
+
+// 校验维护窗口
+func (w *MaintenanceWindow) validate(prefix string) ValidationErrors {
+	var errs ValidationErrors
+	add := func(field, format string, args ...any) {
+		errs = append(errs, ValidationError{Path: joinPath(prefix, field), Message: fmt.Sprintf(format, args...)})
+	}
+	if w.Name == "" {
+		add("name", "name is required")
+	}
+	switch {
+	case w.Cron == "" && w.Start == "":
+		add("cron", "either cron or start is required")
+	case w.Cron != "" && w.Start != "":
+		add("start", "cron and start are mutually exclusive")
+	case w.Start != "":
+		if _, err := time.Parse(time.RFC3339, w.Start); err != nil {
+			add("start", "invalid time %q, expected RFC3339 such as 2025-01-01T02:00:00+08:00", w.Start)
+		}
+	default:
+		if _, err := pkg.ParseCron(w.Cron); err != nil {
+			add("cron", "%v", err)
+		}
+	}
+	if w.Duration <= 0 {
+		add("duration", "must be positive, got %d", w.Duration)
+	}
+	if w.Timezone != "" {
+		if _, err := time.LoadLocation(w.Timezone); err != nil {
+			add("timezone", "unknown timezone %q", w.Timezone)
+		}
+	}
+	for i, pattern := range w.Targets {
+		if _, err := path.Match(pattern, ""); err != nil {
+			add(fmt.Sprintf("targets[%d]", i), "invalid pattern %q", pattern)
+		}
+	}
+	return errs
+}
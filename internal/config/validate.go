@@ -0,0 +1,419 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fuxingjun/balance-bot/pkg"
+)
+
+// ValidationError 单个配置错误, Path 为出错字段的 JSON 路径, 如 tokens[1].address
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// ValidationErrors 配置校验发现的所有错误
+type ValidationErrors []ValidationError
+
+func (errs ValidationErrors) Error() string {
+	lines := make([]string, len(errs))
+	for i, err := range errs {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+var (
+	chainIdPattern       = regexp.MustCompile(`^[1-9][0-9]*$`)
+	telegramTokenPattern = regexp.MustCompile(`^[0-9]+:[A-Za-z0-9_-]{30,}$`)
+	telegramChatPattern  = regexp.MustCompile(`^(-?[0-9]+|@[A-Za-z][A-Za-z0-9_]{4,})$`)
+)
+
+// 通知渠道名称, 与 utils 中的通知渠道注册表一致
+var knownChannels = []string{"telegram", "wecom", "lark"}
+
+// Validate 校验配置, 返回所有错误
+func (config *AppConfig) Validate() error {
+	if errs := config.validate(); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (config *AppConfig) validate() ValidationErrors {
+	var errs ValidationErrors
+	add := func(path, format string, args ...any) {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// 1. 间隔与阈值, 间隔为 0 时已被默认值替换, 负数会导致定时器 panic
+	if config.Interval < 0 {
+		add("interval", "must be positive, got %d", config.Interval)
+	}
+	if config.HealthCheck.Interval < 0 {
+		add("healthCheck.interval", "must be positive, got %d", config.HealthCheck.Interval)
+	}
+	if config.HealthCheck.WarnCount < 0 {
+		add("healthCheck.warnCount", "must be positive, got %d", config.HealthCheck.WarnCount)
+	}
+	services := make([]string, 0, len(config.HealthCheck.Services))
+	for name := range config.HealthCheck.Services {
+		services = append(services, name)
+	}
+	sort.Strings(services)
+	for _, name := range services {
+		service := config.HealthCheck.Services[name]
+		if err := service.Validate(); err != nil {
+			for _, e := range err.(ValidationErrors) {
+				add(joinPath("healthCheck.services."+name, e.Path), "%s", e.Message)
+			}
+		}
+	}
+
+	oi := config.OpenInterestMonitor
+	if oi.Interval < 0 {
+		add("openInterestMonitor.interval", "must be positive, got %d", oi.Interval)
+	}
+	if oi.Window < 0 {
+		add("openInterestMonitor.window", "must be positive, got %d", oi.Window)
+	}
+	if oi.DropThreshold < 0 || oi.DropThreshold >= 1 {
+		add("openInterestMonitor.dropThreshold", "must be between 0 and 1, got %v", oi.DropThreshold)
+	}
+	for i, platform := range oi.Platform {
+		path := fmt.Sprintf("openInterestMonitor.platform[%d]", i)
+		if platform.Platform == "" {
+			add(path+".platform", "platform is required")
+		}
+		if platform.ThresholdUSD < 0 {
+			add(path+".thresholdUSD", "must not be negative")
+		}
+	}
+	if config.SpreadMonitor.Interval < 0 {
+		add("spreadMonitor.interval", "must be positive, got %d", config.SpreadMonitor.Interval)
+	}
+	if config.SpreadMonitor.Threshold < 0 {
+		add("spreadMonitor.threshold", "must not be negative")
+	}
+	if config.SpreadMonitor.FlipMinSpread < 0 {
+		add("spreadMonitor.flipMinSpread", "must not be negative")
+	}
+	if t := config.SpreadMonitor.PriceType; t != "" && t != "last" && t != "mark" {
+		add("spreadMonitor.priceType", "must be last or mark, got %q", t)
+	}
+	for i, platform := range config.FundingMonitor.Platform {
+		path := fmt.Sprintf("fundingMonitor.platform[%d]", i)
+		if platform.Platform == "" {
+			add(path+".platform", "platform is required")
+		}
+		if platform.Threshold < 0 {
+			add(path+".threshold", "must not be negative")
+		}
+		if platform.PredictedThreshold < 0 {
+			add(path+".predictedThreshold", "must not be negative")
+		}
+	}
+
+	// 2. 通知渠道
+	if hook := config.Webhook.Wecom; hook != "" {
+		if err := checkWebhookURL(hook); err != nil {
+			add("webhook.wecom", "%v", err)
+		}
+	}
+	if hook := config.Webhook.Lark; hook != "" {
+		if err := checkWebhookURL(hook); err != nil {
+			add("webhook.lark", "%v", err)
+		}
+	}
+	token, chatId := config.Webhook.TelegramToken, config.Webhook.TelegramChatId
+	if token != "" && !telegramTokenPattern.MatchString(token) {
+		add("webhook.telegram_token", "invalid bot token format, expected <bot id>:<secret>")
+	}
+	if chatId != "" && !telegramChatPattern.MatchString(chatId) {
+		add("webhook.telegram_chat_id", "invalid chat id %q, expected a numeric id or @channel", chatId)
+	}
+	if (token == "") != (chatId == "") {
+		add("webhook", "telegram_token and telegram_chat_id must be configured together")
+	}
+
+	// 3. 链配置, 按 chainId 排序保证输出稳定
+	chainIds := make([]string, 0, len(config.Chains))
+	for id := range config.Chains {
+		chainIds = append(chainIds, id)
+	}
+	sort.Strings(chainIds)
+	for _, id := range chainIds {
+		path := "chains." + id
+		if !chainIdPattern.MatchString(id) {
+			add(path, "chain id must be a positive decimal number")
+		}
+		chain := config.Chains[id]
+		if len(chain.RPC) == 0 {
+			add(path+".rpc", "no rpc configured")
+		}
+		for i, rpc := range chain.RPC {
+			if u, err := url.Parse(rpc); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				add(fmt.Sprintf("%s.rpc[%d]", path, i), "invalid rpc url %q, expected http(s)://...", rpc)
+			}
+		}
+	}
+
+	// 4. 监控地址
+	names := make(map[string]int)
+	keys := make(map[string]int)
+	for i, token := range config.Tokens {
+		path := fmt.Sprintf("tokens[%d]", i)
+		if err := checkAddress(token.Address); err != nil {
+			add(path+".address", "%v", err)
+		}
+		if token.Contract != "" {
+			if err := checkAddress(token.Contract); err != nil {
+				add(path+".contract", "%v", err)
+			}
+		}
+		if !chainIdPattern.MatchString(token.ChainId) {
+			add(path+".chainId", "chain id must be a positive decimal number, got %q", token.ChainId)
+		} else if _, exists := config.Chains[token.ChainId]; !exists {
+			// 未知链在加载时直接报错, 避免请求时才静默失败
+			add(path+".chainId", "unsupported chainId %s, please add it to chains", token.ChainId)
+		}
+		minAmount, err := pkg.ParseAmount(string(token.Min))
+		if err != nil {
+			add(path+".min", "invalid amount %q", token.Min)
+		}
+		if _, err := pkg.ParseAmount(string(token.Max)); token.Max != "" && err != nil {
+			add(path+".max", "invalid amount %q", token.Max)
+		} else if maxAmount, ok := token.MaxAmount(); ok && minAmount.Cmp(maxAmount) >= 0 {
+			add(path+".max", "min %s must be less than max %s", token.Min, token.Max)
+		}
+		if token.Name != "" {
+			if first, exists := names[token.Name]; exists {
+				add(path+".name", "duplicate name %q, already used by tokens[%d]", token.Name, first)
+			} else {
+				names[token.Name] = i
+			}
+		}
+		if first, exists := keys[token.Key()]; exists {
+			add(path, "duplicate of tokens[%d], same chain, address and contract", first)
+		} else {
+			keys[token.Key()] = i
+		}
+	}
+
+	// 5. 接口鉴权
+	tokenNames := make(map[string]int)
+	for i, apiToken := range config.API.Tokens {
+		path := fmt.Sprintf("api.tokens[%d]", i)
+		if apiToken.Token == "" {
+			add(path+".token", "token is required")
+		} else if apiToken.Token == placeholderToken {
+			add(path+".token", "placeholder token %q must be replaced with a random value", placeholderToken)
+		}
+		if apiToken.Name != "" {
+			if first, exists := tokenNames[apiToken.Name]; exists {
+				add(path+".name", "duplicate name %q, already used by api.tokens[%d]", apiToken.Name, first)
+			} else {
+				tokenNames[apiToken.Name] = i
+			}
+		}
+	}
+	for i, origin := range config.API.CORSOrigins {
+		if u, err := url.Parse(origin); origin != "*" && (err != nil || u.Scheme == "" || u.Host == "") {
+			add(fmt.Sprintf("api.corsOrigins[%d]", i), "invalid origin %q, expected scheme://host", origin)
+		}
+	}
+
+	exchangeNames := make([]string, 0, len(config.ExchangeAPI))
+	for name := range config.ExchangeAPI {
+		exchangeNames = append(exchangeNames, name)
+	}
+	sort.Strings(exchangeNames)
+	for _, name := range exchangeNames {
+		if apiKey := config.ExchangeAPI[name]; apiKey.Key == "" || apiKey.Secret == "" {
+			add("exchangeApi."+name, "key and secret are required")
+		}
+	}
+
+	// 6. 维护窗口
+	for i := range config.Maintenance {
+		errs = append(errs, config.Maintenance[i].validate(fmt.Sprintf("maintenance[%d]", i))...)
+	}
+	return errs
+}
+
+// Validate 校验心跳策略, 错误路径相对于策略本身
+func (s *HealthServiceConfig) Validate() error {
+	var errs ValidationErrors
+	for _, field := range []struct {
+		name  string
+		value int
+	}{{"interval", s.Interval}, {"grace", s.Grace}, {"warnCount", s.WarnCount}} {
+		if field.value < 0 {
+			errs = append(errs, ValidationError{Path: field.name, Message: fmt.Sprintf("must not be negative, got %d", field.value)})
+		}
+	}
+	for i, channel := range s.Channels {
+		if !slices.Contains(knownChannels, channel) {
+			errs = append(errs, ValidationError{
+				Path:    fmt.Sprintf("channels[%d]", i),
+				Message: fmt.Sprintf("unknown channel %q, expected one of %s", channel, strings.Join(knownChannels, ", ")),
+			})
+		}
+	}
+	for i, rule := range s.Rules {
+		if _, err := pkg.ParseAssertion(rule); err != nil {
+			errs = append(errs, ValidationError{Path: fmt.Sprintf("rules[%d]", i), Message: err.Error()})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// 校验地址格式, 大小写混合的地址必须符合 EIP-55 校验和
+func checkAddress(address string) error {
+	if address == "" {
+		return errors.New("address is required")
+	}
+	if !pkg.IsHexAddress(address) {
+		return fmt.Errorf("invalid address %q, expected 0x followed by 40 hex characters", address)
+	}
+	if !pkg.IsChecksumAddress(address) {
+		return fmt.Errorf("checksum mismatch for %q, expected %s", address, pkg.ToChecksumAddress(address))
+	}
+	return nil
+}
+
+// 校验 webhook 地址, 只允许 https
+func checkWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid url %q", raw)
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("webhook url must use https, got %q", u.Scheme)
+	}
+	return nil
+}
+
+// 将 JSON 解析错误转换为带行列号和字段路径的错误
+func describeJSONError(data []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		line, col := lineAndColumn(data, syntaxErr.Offset)
+		return ValidationErrors{{Message: fmt.Sprintf("invalid JSON at line %d, column %d: %v", line, col, syntaxErr)}}
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		line, col := lineAndColumn(data, typeErr.Offset)
+		return ValidationErrors{{
+			Path:    typeErr.Field,
+			Message: fmt.Sprintf("expected %s but got JSON %s (line %d, column %d)", typeErr.Type, typeErr.Value, line, col),
+		}}
+	}
+	return err
+}
+
+func lineAndColumn(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := int(offset) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
+
+// 对照配置结构检查未知字段, 拼写错误的字段会被 json 包静默忽略
+func checkUnknownFields(data []byte) ValidationErrors {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil
+	}
+	var errs ValidationErrors
+	walkUnknownFields("", raw, reflect.TypeOf(AppConfig{}), &errs)
+	return errs
+}
+
+func walkUnknownFields(path string, value any, typ reflect.Type, errs *ValidationErrors) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+		fields := make(map[string]reflect.Type, typ.NumField())
+		for i := 0; i < typ.NumField(); i++ {
+			fields[jsonName(typ.Field(i))] = typ.Field(i).Type
+		}
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fieldPath := joinPath(path, key)
+			fieldType, known := fields[key]
+			if !known {
+				*errs = append(*errs, ValidationError{Path: fieldPath, Message: "unknown field"})
+				continue
+			}
+			walkUnknownFields(fieldPath, obj[key], fieldType, errs)
+		}
+	case reflect.Map:
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			walkUnknownFields(joinPath(path, key), obj[key], typ.Elem(), errs)
+		}
+	case reflect.Slice:
+		list, ok := value.([]any)
+		if !ok {
+			return
+		}
+		for i, item := range list {
+			walkUnknownFields(path+"["+strconv.Itoa(i)+"]", item, typ.Elem(), errs)
+		}
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// ValidateFile 读取并校验指定配置文件, 不影响当前生效的配置
+func ValidateFile(path string) (*AppConfig, error) {
+	config, _, err := readConfigFile(path)
+	return config, err
+}
@@ -0,0 +1,276 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fuxingjun/balance-bot/pkg"
+)
+
+// --- 配置文件热加载 ---
+
+// ChangeHandler 配置变更回调, 在新配置生效后调用
+type ChangeHandler func(old, new *AppConfig)
+
+var (
+	changeHandlers []ChangeHandler
+	handlerMutex   sync.Mutex
+)
+
+// 文件的修改时间与大小, 文件不存在时为零值
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// 上次加载时配置文件及其 file: 引用的文件的状态, key 为路径
+var fileStates = map[string]fileState{}
+
+// OnChange 注册配置变更回调
+func OnChange(handler ChangeHandler) {
+	handlerMutex.Lock()
+	defer handlerMutex.Unlock()
+	changeHandlers = append(changeHandlers, handler)
+}
+
+func statFile(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{modTime: info.ModTime(), size: info.Size()}
+}
+
+// 读取配置文件与引用文件的当前状态
+func statFiles(refs []string) map[string]fileState {
+	states := make(map[string]fileState, len(refs)+1)
+	states[configPath] = statFile(configPath)
+	for _, path := range refs {
+		states[path] = statFile(path)
+	}
+	return states
+}
+
+// 记录文件状态, 调用方需持有 configMutex
+func rememberFileState(states map[string]fileState) {
+	fileStates = states
+}
+
+// 判断配置文件或其引用的文件是否发生变化
+func fileChanged() bool {
+	configMutex.RLock()
+	defer configMutex.RUnlock()
+	for path, state := range fileStates {
+		current := statFile(path)
+		if !current.modTime.Equal(state.modTime) || current.size != state.size {
+			return true
+		}
+	}
+	return false
+}
+
+// Watch 定期检查配置文件, 变化时重新加载
+func Watch(interval time.Duration) {
+	pkg.GetLogger().Info("Watching config file", "path", configPath, "interval", interval)
+	for {
+		time.Sleep(interval)
+		if !fileChanged() {
+			continue
+		}
+		if err := Reload(); err != nil {
+			pkg.GetLogger().Error("Config reload failed, keeping previous config", "error", err)
+		}
+	}
+}
+
+// Reload 重新加载配置文件, 新配置校验失败时保留当前配置
+func Reload() error {
+	configMutex.Lock()
+	// 读取前记录文件状态, 无论成功与否都保存, 避免对同一个错误的文件反复报错
+	before := make(map[string]fileState, len(fileStates))
+	for path := range fileStates {
+		before[path] = statFile(path)
+	}
+	config, files, err := readConfigFile(configPath)
+	// 引用的文件可能增减, 新引用的文件在读取后记录; 读取失败时继续监听之前的文件
+	states := statFiles(files)
+	for path, state := range before {
+		if _, exists := states[path]; exists || err != nil {
+			states[path] = state
+		}
+	}
+	rememberFileState(states)
+	if err != nil {
+		configMutex.Unlock()
+		return err
+	}
+	old := configCache
+	configCache = config
+	configMutex.Unlock()
+
+	changes := Diff(old, config)
+	if len(changes) == 0 {
+		pkg.GetLogger().Info("Config reloaded, no changes")
+		return nil
+	}
+	pkg.GetLogger().Info("Config reloaded:\n  " + strings.Join(changes, "\n  "))
+
+	handlerMutex.Lock()
+	handlers := append([]ChangeHandler(nil), changeHandlers...)
+	handlerMutex.Unlock()
+	for _, handler := range handlers {
+		handler(old, config)
+	}
+	return nil
+}
+
+// Key 地址配置的唯一标识
+func (t *TokenConfig) Key() string {
+	return t.ChainId + ":" + strings.ToLower(t.Address) + ":" + strings.ToLower(t.Contract)
+}
+
+// 地址配置的展示名称
+func (t *TokenConfig) label() string {
+	label := t.Address
+	if t.Name != "" {
+		label = t.Name + " " + label
+	}
+	if t.Contract != "" {
+		label += " token " + t.Contract
+	}
+	return label + " on chain " + t.ChainId
+}
+
+// TokenChanges 对比新旧配置的地址列表, 返回新增、删除与修改的地址
+func TokenChanges(old, new *AppConfig) (added, removed, changed []TokenConfig) {
+	oldTokens := make(map[string]TokenConfig)
+	if old != nil {
+		for _, token := range old.Tokens {
+			oldTokens[token.Key()] = token
+		}
+	}
+	newTokens := make(map[string]struct{})
+	for _, token := range new.Tokens {
+		newTokens[token.Key()] = struct{}{}
+		prev, exists := oldTokens[token.Key()]
+		if !exists {
+			added = append(added, token)
+		} else if !reflect.DeepEqual(prev, token) {
+			changed = append(changed, token)
+		}
+	}
+	if old != nil {
+		for _, token := range old.Tokens {
+			if _, exists := newTokens[token.Key()]; !exists {
+				removed = append(removed, token)
+			}
+		}
+	}
+	return added, removed, changed
+}
+
+// Diff 生成可读的配置变更列表, 敏感字段只提示是否变化
+func Diff(old, new *AppConfig) []string {
+	if old == nil {
+		return []string{"config loaded"}
+	}
+	var changes []string
+
+	// 1. 地址列表
+	oldTokens := make(map[string]TokenConfig)
+	for _, token := range old.Tokens {
+		oldTokens[token.Key()] = token
+	}
+	added, removed, changed := TokenChanges(old, new)
+	for _, token := range added {
+		changes = append(changes, fmt.Sprintf("+ token %s (min %s, max %s)", token.label(), token.Min, token.Max))
+	}
+	for _, token := range removed {
+		changes = append(changes, fmt.Sprintf("- token %s", token.label()))
+	}
+	for _, token := range changed {
+		prev := oldTokens[token.Key()]
+		var parts []string
+		if prev.Name != token.Name {
+			parts = append(parts, fmt.Sprintf("name %q -> %q", prev.Name, token.Name))
+		}
+		if prev.Min != token.Min {
+			parts = append(parts, fmt.Sprintf("min %s -> %s", prev.Min, token.Min))
+		}
+		if prev.Max != token.Max {
+			parts = append(parts, fmt.Sprintf("max %s -> %s", prev.Max, token.Max))
+		}
+		if len(parts) == 0 {
+			parts = append(parts, "settings changed")
+		}
+		changes = append(changes, fmt.Sprintf("~ token %s: %s", token.label(), strings.Join(parts, ", ")))
+	}
+
+	// 2. 通知渠道, 不输出具体值
+	webhooks := []struct {
+		name     string
+		old, new string
+	}{
+		{"webhook.wecom", old.Webhook.Wecom, new.Webhook.Wecom},
+		{"webhook.lark", old.Webhook.Lark, new.Webhook.Lark},
+		{"webhook.telegram", old.Webhook.TelegramToken + old.Webhook.TelegramChatId, new.Webhook.TelegramToken + new.Webhook.TelegramChatId},
+	}
+	for _, hook := range webhooks {
+		switch {
+		case hook.old == hook.new:
+		case hook.old == "":
+			changes = append(changes, hook.name+" configured")
+		case hook.new == "":
+			changes = append(changes, hook.name+" removed")
+		default:
+			changes = append(changes, hook.name+" changed")
+		}
+	}
+
+	// 3. 其它配置项, 简单类型输出新旧值
+	oldValue := reflect.ValueOf(*old)
+	newValue := reflect.ValueOf(*new)
+	for i := 0; i < oldValue.NumField(); i++ {
+		field := oldValue.Type().Field(i)
+		if field.Name == "Tokens" || field.Name == "Webhook" {
+			continue
+		}
+		changes = append(changes, diffValue(jsonName(field), oldValue.Field(i), newValue.Field(i))...)
+	}
+	return changes
+}
+
+// 递归对比结构体字段, 包含 secret / token 的字段不输出具体值
+func diffValue(path string, old, new reflect.Value) []string {
+	if reflect.DeepEqual(old.Interface(), new.Interface()) {
+		return nil
+	}
+	if old.Kind() == reflect.Struct {
+		var changes []string
+		for i := 0; i < old.NumField(); i++ {
+			changes = append(changes, diffValue(path+"."+jsonName(old.Type().Field(i)), old.Field(i), new.Field(i))...)
+		}
+		return changes
+	}
+	lower := strings.ToLower(path)
+	switch {
+	case strings.Contains(lower, "secret") || strings.Contains(lower, "token"):
+		return []string{path + " changed"}
+	case old.Kind() == reflect.Map || old.Kind() == reflect.Slice:
+		return []string{path + " changed"}
+	}
+	return []string{fmt.Sprintf("%s: %v -> %v", path, old.Interface(), new.Interface())}
+}
+
+// 字段的 json 名称
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"sync"
+	"time"
+
+	"github.com/fuxingjun/balance-bot/internal/config"
+)
+
+// --- 告警状态机: 每个告警条件经历 firing -> resolved 生命周期 ---
+
+// AlertState 单个告警条件的状态
+type AlertState struct {
+	Key            string    `json:"key"`
+	Firing         bool      `json:"firing"`
+	FiredAt        time.Time `json:"firedAt"`
+	LastNotifiedAt time.Time `json:"lastNotifiedAt"`
+	NotifyCount    int       `json:"notifyCount"`
+}
+
+// AlertPolicy 告警通知策略
+type AlertPolicy struct {
+	Renotify  time.Duration // 重复通知间隔, 窗口内的重复告警会被抑制, 0 表示每次都通知
+	MaxNotify int           // 单次告警最多通知次数, 0 表示不限
+}
+
+var (
+	alertStore = make(map[string]*AlertState)
+	alertMutex sync.Mutex
+)
+
+// fireAlert 将告警置为 firing, 返回本次是否需要发送通知
+func fireAlert(key string, policy AlertPolicy) bool {
+	return fireAlertIf(key, policy, nil)
+}
+
+// fireAlertIf 同 fireAlert, 策略允许通知后再由 allow 决定是否通知, allow 返回 false 时不记为已通知
+func fireAlertIf(key string, policy AlertPolicy, allow func() bool) bool {
+	alertMutex.Lock()
+	defer alertMutex.Unlock()
+
+	now := time.Now()
+	state, exists := alertStore[key]
+	if !exists || !state.Firing {
+		state = &AlertState{Key: key, Firing: true, FiredAt: now}
+		alertStore[key] = state
+		saveState(stateBucketAlerts, key, state)
+	}
+	if policy.MaxNotify > 0 && state.NotifyCount >= policy.MaxNotify {
+		return false
+	}
+	if state.NotifyCount > 0 && now.Sub(state.LastNotifiedAt) < policy.Renotify {
+		return false
+	}
+	if allow != nil && !allow() {
+		return false
+	}
+	state.NotifyCount++
+	state.LastNotifiedAt = now
+	saveState(stateBucketAlerts, key, state)
+	return true
+}
+
+// resolveAlert 将告警置为 resolved, 返回恢复前的状态; 只有之前处于告警且发送过通知时 ok 为 true, 调用方据此发送恢复通知
+func resolveAlert(key string) (AlertState, bool) {
+	alertMutex.Lock()
+	defer alertMutex.Unlock()
+
+	state, exists := alertStore[key]
+	if !exists {
+		return AlertState{}, false
+	}
+	delete(alertStore, key)
+	deleteState(stateBucketAlerts, key)
+	return *state, state.Firing && state.NotifyCount > 0
+}
+
+// evaluateAlert 按条件触发或恢复告警, 返回是否需要通知以及是否刚恢复
+// 重复通知间隔内不再通知, 24 小时内最多通知 notifyCount 次, 被次数限制抑制的通知不计入告警的通知次数
+func evaluateAlert(key string, alerting bool, policy AlertPolicy, notifyCount int) (bool, bool) {
+	if !alerting {
+		_, recovered := resolveAlert(key)
+		return false, recovered
+	}
+	return fireAlertIf(key, policy, func() bool { return allowNotify(key, notifyCount) }), false
+}
+
+// isAlertFiring 判断告警是否处于 firing
+func isAlertFiring(key string) bool {
+	alertMutex.Lock()
+	defer alertMutex.Unlock()
+	state, exists := alertStore[key]
+	return exists && state.Firing
+}
+
+// 默认告警策略, 重复通知间隔取自配置
+func defaultAlertPolicy() AlertPolicy {
+	cfg, err := config.LoadConfig()
+	if err != nil || cfg == nil {
+		return AlertPolicy{Renotify: time.Hour}
+	}
+	return AlertPolicy{Renotify: time.Duration(cfg.Alert.RenotifyInterval) * time.Second}
+}
@@ -0,0 +1,146 @@
+package core
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fuxingjun/balance-bot/internal/config"
+	"github.com/fuxingjun/balance-bot/pkg"
+	"github.com/gofiber/fiber/v2"
+)
+
+// --- HTTP 接口鉴权: Bearer token 权限 + 可选的 HMAC 请求签名 ---
+
+// 接口权限
+const (
+	ScopeHealth  = "health"  // 上报心跳
+	ScopeMonitor = "monitor" // 提交监控交易对
+	ScopeStatus  = "status"  // 查询状态与指标
+)
+
+// 签名请求头
+const (
+	headerTimestamp = "X-Timestamp"
+	headerSignature = "X-Signature"
+)
+
+// 已使用的签名, 防止重放; 条目 TTL 按 maxSkew 计算, 见 verifySignature
+var usedSignatures = pkg.NewTTLCache(10 * time.Minute)
+
+// Auth 返回校验指定权限的中间件, 未配置 token 与签名密钥时不做校验
+func Auth(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		cfg, err := config.LoadConfig()
+		if err != nil || cfg == nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed to load config",
+			})
+		}
+		if len(cfg.API.Tokens) > 0 {
+			token, ok := matchToken(cfg.API.Tokens, c.Get(fiber.HeaderAuthorization))
+			if !ok {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "invalid token",
+				})
+			}
+			if !hasScope(token, scope) {
+				pkg.GetLogger().Warn("Token scope denied", "token", token.Name, "scope", scope, "path", c.Path())
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+					"error": "insufficient scope",
+				})
+			}
+		}
+		// 配置了 token 时只有写接口需要签名, 方便 Prometheus 等只读客户端凭 token 抓取
+		// 只配置签名密钥时 GET 也需要签名, 否则只读接口没有任何鉴权
+		if cfg.API.HMAC.Secret != "" && (c.Method() != fiber.MethodGet || len(cfg.API.Tokens) == 0) {
+			if err := verifySignature(c, cfg.API.HMAC); err != nil {
+				pkg.GetLogger().Warn("Signature verification failed", "path", c.Path(), "error", err)
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+		}
+		return c.Next()
+	}
+}
+
+// 匹配 Authorization: Bearer <token>
+func matchToken(tokens []config.APIToken, header string) (config.APIToken, bool) {
+	value, found := strings.CutPrefix(header, "Bearer ")
+	if !found || value == "" {
+		return config.APIToken{}, false
+	}
+	for _, token := range tokens {
+		if token.Token != "" && subtle.ConstantTimeCompare([]byte(token.Token), []byte(value)) == 1 {
+			return token, true
+		}
+	}
+	return config.APIToken{}, false
+}
+
+func hasScope(token config.APIToken, scope string) bool {
+	return len(token.Scopes) == 0 || slices.Contains(token.Scopes, "*") || slices.Contains(token.Scopes, scope)
+}
+
+// 校验签名: hex(HMAC-SHA256(secret, timestamp + "\n" + method + "\n" + path + "\n" + body))
+func verifySignature(c *fiber.Ctx, cfg config.HMACConfig) error {
+	timestamp := c.Get(headerTimestamp)
+	signature := c.Get(headerSignature)
+	if timestamp == "" || signature == "" {
+		return fmt.Errorf("missing signature")
+	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp")
+	}
+	// 兼容毫秒时间戳
+	if ts > 1e12 {
+		ts /= 1000
+	}
+	skew := time.Since(time.Unix(ts, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	maxSkew := time.Duration(cfg.MaxSkew) * time.Second
+	if skew > maxSkew {
+		return fmt.Errorf("timestamp expired")
+	}
+	expected := SignRequest(cfg.Secret, timestamp, c.Method(), c.Path(), c.Body())
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(signature))) {
+		return fmt.Errorf("invalid signature")
+	}
+	// 时间戳在前后 maxSkew 内都有效, 签名需保留 2*maxSkew 才能覆盖整个有效期
+	if !usedSignatures.SetIfAbsent(expected, true, 2*maxSkew) {
+		return fmt.Errorf("replayed request")
+	}
+	return nil
+}
+
+// SignRequest 计算请求签名, 供客户端参考实现
+func SignRequest(secret, timestamp, method, path string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + method + "\n" + path + "\n"))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// AllowOrigin 判断来源是否允许跨域, 每次读取当前配置以支持热加载
+func AllowOrigin(origin string) bool {
+	cfg, err := config.LoadConfig()
+	if err != nil || cfg == nil {
+		return false
+	}
+	for _, allowed := range cfg.API.CORSOrigins {
+		if allowed == "*" || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
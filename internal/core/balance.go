@@ -2,71 +2,348 @@ package core
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/fuxingjun/balance-bot/internal/config"
+	"github.com/fuxingjun/balance-bot/internal/metrics"
 	"github.com/fuxingjun/balance-bot/internal/utils"
 	"github.com/fuxingjun/balance-bot/pkg"
 )
 
-func checkBalanceItem(item *config.TokenConfig) error {
-	if item.Address == "" {
-		panic("address cannot be empty")
-	}
-	resp, err := GetEVMBalance(item.Address, item.ChainId)
-	// 地址只显示开始和结尾
-	address := item.Address
-	if len(address) > 10 {
-		address = address[:6] + "**" + address[len(address)-4:]
-	}
-	// 有name的话在地址后面显示
+// 地址只显示开始和结尾, 有name的话在地址后面显示
+func formatTokenLabel(item *config.TokenConfig) string {
+	address := utils.MaskAddress(item.Address)
 	if item.Name != "" {
 		address = address + "(" + item.Name + ")"
 	}
-	if err != nil {
-		pkg.GetLogger().Error(fmt.Sprintf("Get balance error for %s on chain %s: %v", address, item.ChainId, err))
-		return err
+	// 代币余额标注合约地址
+	if item.Contract != "" {
+		address = address + " token " + utils.MaskAddress(item.Contract)
+	}
+	return address
+}
+
+// 检查单个地址的余额是否超出阈值
+func checkBalanceItem(item *config.TokenConfig, resp pkg.Amount) {
+	address := formatTokenLabel(item)
+	// 原生代币显示链的代币符号
+	unit := ""
+	if item.Contract == "" {
+		if chain, err := getChainConfig(item.ChainId); err == nil && chain.Symbol != "" {
+			unit = " " + chain.Symbol
+		}
 	}
-	pkg.GetLogger().Info(fmt.Sprintf("Balance for %s on chain %s: %f", address, item.ChainId, resp))
+	pkg.GetLogger().Info(fmt.Sprintf("Balance for %s on chain %s: %s%s", address, item.ChainId, resp.String(), unit))
+	metrics.Balance.WithLabelValues(item.ChainId, item.Address, item.Name, item.Contract).Set(resp.Float64())
+	// 阈值与余额均为定点数, 比较不经过浮点数; 展示时保留 6 位小数并向零截断
 	msg := ""
-	if resp < item.Min {
-		msg = fmt.Sprintf("⚠️ Balance for %s on chain %s is below minimum %f: %f", address, item.ChainId, item.Min, resp)
+	status := balanceStatusOK
+	minAmount := item.MinAmount()
+	maxAmount, hasMax := item.MaxAmount()
+	if resp.Cmp(minAmount) < 0 {
+		status = balanceStatusBelowMin
+		msg = fmt.Sprintf("⚠️ Balance for %s on chain %s is below minimum %s: %s%s", address, item.ChainId, minAmount.FormatPretty(6), resp.FormatPretty(6), unit)
 		pkg.GetLogger().Warn(msg)
-	} else if resp > item.Max {
-		msg = fmt.Sprintf("⚠️ Balance for %s on chain %s is above maximum %f: %f", address, item.ChainId, item.Max, resp)
+	} else if hasMax && resp.Cmp(maxAmount) > 0 {
+		status = balanceStatusAboveMax
+		msg = fmt.Sprintf("⚠️ Balance for %s on chain %s is above maximum %s: %s%s", address, item.ChainId, maxAmount.FormatPretty(6), resp.FormatPretty(6), unit)
 		pkg.GetLogger().Warn(msg)
 	}
+	recordBalanceReading(item, resp.String(), status, nil)
 	if msg != "" {
+		// 低于最小值与高于最大值是不同的告警, 余额直接跳到另一侧时先恢复原方向的告警, 新方向立即通知
+		for _, other := range []string{balanceStatusBelowMin, balanceStatusAboveMax} {
+			if other != status {
+				resolveAlert(balanceStatusAlertKey(item, other))
+			}
+		}
+		alertKey := balanceStatusAlertKey(item, status)
+		// 维护窗口内不告警
+		if inMaintenance(item.Address, item.Name) {
+			pkg.GetLogger().Info("Balance alert suppressed by maintenance window", "key", alertKey)
+			return
+		}
+		// 重复通知间隔内的相同告警不再发送
+		if !fireAlert(alertKey, defaultAlertPolicy()) {
+			pkg.GetLogger().Debug("Balance alert suppressed", "key", alertKey)
+			return
+		}
 		// 发送通知
 		err := utils.SendMessage(msg)
 		if err != nil {
 			pkg.GetLogger().Error(fmt.Sprintf("Send message error: %v\n", err))
 		}
+		return
 	}
-	return nil
+	// 余额回到 [min, max] 区间, 发送恢复通知
+	if resolveBalanceAlerts(item) {
+		msg = fmt.Sprintf("✅ Balance for %s on chain %s is back within range: %s%s", address, item.ChainId, resp.FormatPretty(6), unit)
+		pkg.GetLogger().Info(msg)
+		if err := utils.SendMessage(msg); err != nil {
+			pkg.GetLogger().Error(fmt.Sprintf("Send message error: %v\n", err))
+		}
+	}
+}
+
+// 余额状态
+const (
+	balanceStatusPending  = "pending" // 尚未查询
+	balanceStatusOK       = "ok"
+	balanceStatusBelowMin = "below_min"
+	balanceStatusAboveMax = "above_max"
+	balanceStatusError    = "error"
+)
+
+// BalanceReading 地址最近一次余额读数
+type BalanceReading struct {
+	Name      string `json:"name"`
+	Address   string `json:"address"`
+	ChainId   string `json:"chainId"`
+	Contract  string `json:"contract,omitempty"`
+	Balance   string `json:"balance,omitempty"`
+	Min       string `json:"min"`
+	Max       string `json:"max,omitempty"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	UpdatedAt string `json:"updatedAt,omitempty"`
 }
 
+// 最近一次余额读数, key 同 balanceAlertKey
+var (
+	balanceReadings = make(map[string]BalanceReading)
+	balanceMutex    sync.RWMutex
+)
+
+func recordBalanceReading(item *config.TokenConfig, balance, status string, err error) {
+	reading := BalanceReading{
+		Balance:   balance,
+		Status:    status,
+		UpdatedAt: time.Now().Format(time.RFC3339),
+	}
+	if err != nil {
+		reading.Error = err.Error()
+	}
+	balanceMutex.Lock()
+	defer balanceMutex.Unlock()
+	balanceReadings[balanceAlertKey(item)] = reading
+}
+
+// 获取配置中每个地址的最近一次读数, 尚未查询的地址状态为 pending
+func getBalanceReadings(tokens []config.TokenConfig) []BalanceReading {
+	balanceMutex.RLock()
+	defer balanceMutex.RUnlock()
+	result := make([]BalanceReading, 0, len(tokens))
+	for i := range tokens {
+		item := &tokens[i]
+		reading, exists := balanceReadings[balanceAlertKey(item)]
+		if !exists {
+			reading = BalanceReading{Status: balanceStatusPending}
+		}
+		reading.Name = item.Name
+		reading.Address = item.Address
+		reading.ChainId = item.ChainId
+		reading.Contract = item.Contract
+		reading.Min = string(item.Min)
+		if _, ok := item.MaxAmount(); ok {
+			reading.Max = string(item.Max)
+		}
+		result = append(result, reading)
+	}
+	return result
+}
+
+// 地址的唯一标识, 也是余额告警 key 的前缀
+func balanceAlertKey(item *config.TokenConfig) string {
+	return "balance:" + item.ChainId + ":" + strings.ToLower(item.Address) + ":" + strings.ToLower(item.Contract)
+}
+
+// 余额告警 key, 按 below_min / above_max 区分
+func balanceStatusAlertKey(item *config.TokenConfig, status string) string {
+	return balanceAlertKey(item) + ":" + status
+}
+
+// 恢复地址的所有余额告警, 任一告警发送过通知时返回 true
+func resolveBalanceAlerts(item *config.TokenConfig) bool {
+	notified := false
+	for _, status := range []string{balanceStatusBelowMin, balanceStatusAboveMax} {
+		if _, ok := resolveAlert(balanceStatusAlertKey(item, status)); ok {
+			notified = true
+		}
+	}
+	return notified
+}
+
+// 批量查询同一条链上所有地址的余额, 单个地址失败不影响其它地址
+func checkChainBalances(chainId string, items []config.TokenConfig) {
+	chain, err := getChainConfig(chainId)
+	if err != nil {
+		pkg.GetLogger().Error(fmt.Sprintf("Check balance error on chain %s: %v", chainId, err))
+		return
+	}
+	// 1. 批量查询尚未缓存的合约精度
+	var decimalsContracts []string
+	pending := make(map[string]struct{})
+	for _, item := range items {
+		if item.Contract == "" || item.Decimals != nil {
+			continue
+		}
+		if _, exists := getCachedDecimals(item.Contract, chainId); exists {
+			continue
+		}
+		if _, exists := pending[item.Contract]; !exists {
+			pending[item.Contract] = struct{}{}
+			decimalsContracts = append(decimalsContracts, item.Contract)
+		}
+	}
+	if len(decimalsContracts) > 0 {
+		calls := make([]rpcCall, 0, len(decimalsContracts))
+		for _, contract := range decimalsContracts {
+			calls = append(calls, erc20DecimalsCall(contract))
+		}
+		results, err := sendRPCBatch(chainId, calls)
+		if err != nil {
+			pkg.GetLogger().Error(fmt.Sprintf("Get decimals error on chain %s: %v", chainId, err))
+			return
+		}
+		for i, res := range results {
+			if res.Err == nil {
+				_, res.Err = cacheDecimalsResult(decimalsContracts[i], chainId, res.Result)
+			}
+			if res.Err != nil {
+				pkg.GetLogger().Error(fmt.Sprintf("Get decimals error for %s on chain %s: %v", decimalsContracts[i], chainId, res.Err))
+			}
+		}
+	}
+
+	// 2. 批量查询余额, 精度未知的代币跳过
+	var queried []config.TokenConfig
+	var decimals []uint8
+	var calls []rpcCall
+	failed := 0
+	for _, item := range items {
+		if item.Contract == "" {
+			queried = append(queried, item)
+			decimals = append(decimals, chain.Decimals)
+			calls = append(calls, nativeBalanceCall(item.Address))
+			continue
+		}
+		dec, exists := getCachedDecimals(item.Contract, chainId)
+		if item.Decimals != nil {
+			dec, exists = *item.Decimals, true
+		}
+		if !exists {
+			failed++
+			pkg.GetLogger().Error(fmt.Sprintf("Get balance error for %s on chain %s: unknown decimals", formatTokenLabel(&item), chainId))
+			recordBalanceReading(&item, "", balanceStatusError, fmt.Errorf("unknown decimals"))
+			continue
+		}
+		queried = append(queried, item)
+		decimals = append(decimals, dec)
+		calls = append(calls, erc20BalanceCall(item.Address, item.Contract))
+	}
+	if len(calls) == 0 {
+		return
+	}
+	results, err := sendRPCBatch(chainId, calls)
+	if err != nil {
+		pkg.GetLogger().Error(fmt.Sprintf("Check balance error on chain %s: %v", chainId, err))
+		return
+	}
+	for i, res := range results {
+		item := queried[i]
+		balance, err := res.Result, res.Err
+		var amount pkg.Amount
+		if err == nil {
+			amount, err = parseBalanceResult(balance, decimals[i])
+		}
+		if err != nil {
+			failed++
+			pkg.GetLogger().Error(fmt.Sprintf("Get balance error for %s on chain %s: %v", formatTokenLabel(&item), chainId, err))
+			recordBalanceReading(&item, "", balanceStatusError, err)
+			continue
+		}
+		checkBalanceItem(&item, amount)
+	}
+	pkg.GetLogger().Debug("Chain balance check finished", "chain", chainId, "total", len(items), "failed", failed)
+}
+
+// 余额检测定时器, 配置变更时重设间隔
+var (
+	balanceTicker *time.Ticker
+	tickerMutex   sync.Mutex
+)
+
+// CheckBalance 立即检测一次所有地址的余额, 并按配置的间隔定时检测
 func CheckBalance() {
 	appConfig, err := config.LoadConfig()
 	if err != nil {
 		panic(err)
 	}
-	for _, item := range appConfig.Tokens {
-		go func(it config.TokenConfig) {
-			err := checkBalanceItem(&it)
-			if err != nil {
-				pkg.GetLogger().Error(fmt.Sprintf("Check balance error: %v\n", err))
-			}
-		}(item)
+	runBalanceCheck(appConfig.Tokens)
+
+	tickerMutex.Lock()
+	defer tickerMutex.Unlock()
+	if balanceTicker != nil {
+		return
 	}
-	// 设置定时器
-	interval := appConfig.Interval
+	balanceTicker = time.NewTicker(balanceInterval(appConfig))
+	pkg.GetLogger().Info(fmt.Sprintf("Next check in %d seconds...\n", appConfig.Interval))
+	go func() {
+		for range balanceTicker.C {
+			cfg, err := config.LoadConfig()
+			if err != nil || cfg == nil {
+				pkg.GetLogger().Error(fmt.Sprintf("Load config error: %v\n", err))
+				continue
+			}
+			runBalanceCheck(cfg.Tokens)
+			pkg.GetLogger().Info(fmt.Sprintf("Next check in %d seconds...\n", cfg.Interval))
+		}
+	}()
+}
+
+func balanceInterval(cfg *config.AppConfig) time.Duration {
+	interval := cfg.Interval
 	if interval <= 0 {
 		interval = 30 // 默认 30 秒
 	}
-	pkg.GetLogger().Info(fmt.Sprintf("Next check in %d seconds...\n", interval))
-	go func() {
-		time.Sleep(time.Duration(interval) * time.Second)
-		CheckBalance()
-	}()
+	return time.Duration(interval) * time.Second
+}
+
+// 重设余额检测间隔
+func rescheduleBalanceCheck(cfg *config.AppConfig) {
+	tickerMutex.Lock()
+	defer tickerMutex.Unlock()
+	if balanceTicker != nil {
+		balanceTicker.Reset(balanceInterval(cfg))
+	}
+}
+
+// 按链分组, 每条链使用批量请求
+func runBalanceCheck(tokens []config.TokenConfig) {
+	tokensByChain := make(map[string][]config.TokenConfig)
+	for _, item := range tokens {
+		tokensByChain[item.ChainId] = append(tokensByChain[item.ChainId], item)
+	}
+	for chainId, items := range tokensByChain {
+		go checkChainBalances(chainId, items)
+	}
+}
+
+// 清理已移除地址的读数、告警与指标
+func forgetBalanceItem(item *config.TokenConfig) {
+	key := balanceAlertKey(item)
+	balanceMutex.Lock()
+	delete(balanceReadings, key)
+	balanceMutex.Unlock()
+	resolveBalanceAlerts(item)
+	metrics.Balance.DeleteLabelValues(item.ChainId, item.Address, item.Name, item.Contract)
+}
+
+// 地址配置修改后, 名称或地址大小写变化会产生新的指标序列, 删除旧序列避免一直导出旧值
+func forgetBalanceMetric(prev, item *config.TokenConfig) {
+	if prev.Name != item.Name || prev.Address != item.Address || prev.Contract != item.Contract {
+		metrics.Balance.DeleteLabelValues(prev.ChainId, prev.Address, prev.Name, prev.Contract)
+	}
 }
@@ -3,50 +3,85 @@ package core
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"reflect"
-	"sync"
+	"strings"
 
+	"github.com/fuxingjun/balance-bot/internal/config"
 	"github.com/fuxingjun/balance-bot/pkg"
 )
 
-var BSC_RPC = []string{
-	"https://bsc-dataseed.bnbchain.org",
-	"https://bsc-dataseed.nariox.org",
-	"https://bsc-dataseed.defibit.io",
-	"https://bsc-dataseed.ninicoin.io",
-	"https://bsc.nodereal.io",
+// 获取链配置
+func getChainConfig(chainId string) (config.ChainConfig, error) {
+	appConfig, err := config.LoadConfig()
+	if err != nil {
+		return config.ChainConfig{}, err
+	}
+	if appConfig == nil {
+		return config.ChainConfig{}, fmt.Errorf("config is nil")
+	}
+	chain, exists := appConfig.GetChain(chainId)
+	if !exists {
+		return config.ChainConfig{}, fmt.Errorf("unsupported chain %s", chainId)
+	}
+	return chain, nil
 }
 
-var (
-	bscPoint = 0
-	bscMutex sync.Mutex
+// ERC20 方法选择器
+const (
+	erc20BalanceOfSelector = "0x70a08231" // balanceOf(address)
+	erc20DecimalsSelector  = "0x313ce567" // decimals()
 )
 
-func GetRPC(chainId string) string {
-	if chainId == "56" {
-		bscMutex.Lock()
-		defer bscMutex.Unlock()
+// 合约精度缓存, key 为 chainId:合约地址(小写), 精度不会变化因此不过期
+var decimalsCache = pkg.NewSimpleCache(nil)
 
-		if bscPoint >= len(BSC_RPC) {
-			bscPoint = 0
-		}
-		rpc := BSC_RPC[bscPoint]
-		bscPoint++
-		return rpc
-	}
-	return ""
+// rpcCall 单个 JSON-RPC 调用
+type rpcCall struct {
+	Method string
+	Params []any
 }
 
-// 获取钱包地址在目标链上的原生代币余额（比如 BNB 于 BSC,ETH 于 Ethereum）
-func GetEVMBalance(walletAddress, chainId string) (float64, error) {
-	params := map[string]any{
+// 构造带唯一 id 的 JSON-RPC 请求体
+func newRPCParams(call rpcCall) map[string]any {
+	return map[string]any{
 		"jsonrpc": "2.0",
-		"method":  "eth_getBalance",
-		"params":  []any{walletAddress, "latest"},
+		"method":  call.Method,
+		"params":  call.Params,
 		"id":      pkg.GetSimpleId(),
 	}
+}
+
+func nativeBalanceCall(walletAddress string) rpcCall {
+	return rpcCall{Method: "eth_getBalance", Params: []any{walletAddress, "latest"}}
+}
+
+func erc20BalanceCall(walletAddress, contract string) rpcCall {
+	// balanceOf(address) 参数为左侧补零到 32 字节的地址
+	data := erc20BalanceOfSelector + fmt.Sprintf("%064s", strings.ToLower(strings.TrimPrefix(walletAddress, "0x")))
+	return rpcCall{Method: "eth_call", Params: []any{map[string]any{"to": contract, "data": data}, "latest"}}
+}
+
+func erc20DecimalsCall(contract string) rpcCall {
+	return rpcCall{Method: "eth_call", Params: []any{map[string]any{"to": contract, "data": erc20DecimalsSelector}, "latest"}}
+}
+
+func getCachedDecimals(contract, chainId string) (uint8, bool) {
+	if cached, exists := decimalsCache.Get(chainId + ":" + strings.ToLower(contract)); exists {
+		return cached.(uint8), true
+	}
+	return 0, false
+}
 
-	return sendBalanceRequest(params, 18, chainId)
+// 解析 decimals() 返回值并写入缓存
+func cacheDecimalsResult(contract, chainId, result string) (uint8, error) {
+	value := pkg.HexToBigInt(result)
+	if result == "0x" || !value.IsUint64() || value.Uint64() > 255 {
+		return 0, fmt.Errorf("invalid decimals for contract %s: %s", contract, result)
+	}
+	decimals := uint8(value.Uint64())
+	decimalsCache.Set(chainId+":"+strings.ToLower(contract), decimals)
+	return decimals, nil
 }
 
 // 定义RPC响应结构体，注意Error字段是一个对象
@@ -59,30 +94,113 @@ type RPCResponseT[T any] struct {
 	} `json:"error,omitempty"`
 }
 
-func sendBalanceRequest(params map[string]any, decimals uint64, chainId string) (float64, error) {
-	resp, err := pkg.GetHTTPClient().SendPostRequest(GetRPC(chainId), params, nil, nil)
+// 向指定节点发送单个 JSON-RPC 请求
+func postRPC(url string, params map[string]any) (string, error) {
+	resp, err := pkg.GetHTTPClient().SendPostRequest(url, params, nil, nil)
 	if err != nil {
-		return 0, err
+		return "", err
 	}
 	var data RPCResponseT[*string]
 	if err := json.Unmarshal(resp, &data); err != nil {
-		return 0, fmt.Errorf("JSON unmarshal failed: %v", err)
+		return "", fmt.Errorf("JSON unmarshal failed: %v", err)
 	}
 	// === 1. 验证 id ===
 	if !reflect.DeepEqual(data.Id, params["id"]) {
-		return 0, fmt.Errorf("id mismatch: expected %v, got %v", params["id"], data.Id)
+		return "", fmt.Errorf("id mismatch: expected %v, got %v", params["id"], data.Id)
 	}
 	// === 2. 检查 error 字段 ===
 	if data.Error != nil {
-		return 0, fmt.Errorf("RPC error: %s", data.Error.Message)
+		return "", &rpcError{Code: data.Error.Code, Message: data.Error.Message}
 	}
 	if data.Result == nil {
-		return 0, fmt.Errorf("balance not found")
+		return "", fmt.Errorf("result not found")
+	}
+	return *data.Result, nil
+}
+
+// rpcResult 批量请求中单个调用的结果
+type rpcResult struct {
+	Result string
+	Err    error
+}
+
+// 发送 JSON-RPC 批量请求, 按 batchSize 分批, 结果按 id 匹配回调用顺序, 单个调用的错误记录在对应结果中
+func sendRPCBatch(chainId string, calls []rpcCall) ([]rpcResult, error) {
+	chain, err := getChainConfig(chainId)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := getRPCPool(chainId)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]rpcResult, len(calls))
+	for start := 0; start < len(calls); start += chain.BatchSize {
+		end := min(start+chain.BatchSize, len(calls))
+		payload := make([]map[string]any, 0, end-start)
+		for _, call := range calls[start:end] {
+			payload = append(payload, newRPCParams(call))
+		}
+		var batch map[string]rpcResult
+		err := pool.Do(func(url string) error {
+			var callErr error
+			batch, callErr = postRPCBatch(url, payload)
+			return callErr
+		})
+		for i, params := range payload {
+			if err != nil {
+				results[start+i] = rpcResult{Err: err}
+				continue
+			}
+			res, exists := batch[params["id"].(string)]
+			if !exists {
+				res = rpcResult{Err: fmt.Errorf("no response for id %s", params["id"])}
+			}
+			results[start+i] = res
+		}
+	}
+	return results, nil
+}
+
+// 向指定节点发送批量 JSON-RPC 请求, 返回以 id 为 key 的结果
+func postRPCBatch(url string, payload []map[string]any) (map[string]rpcResult, error) {
+	resp, err := pkg.GetHTTPClient().SendPostRequest(url, payload, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var data []RPCResponseT[*string]
+	if err := json.Unmarshal(resp, &data); err != nil {
+		// 部分节点不支持批量请求, 会返回单个错误对象, 按节点错误处理以切换节点
+		var single RPCResponseT[*string]
+		if json.Unmarshal(resp, &single) == nil && single.Error != nil {
+			return nil, fmt.Errorf("batch not supported: %s", single.Error.Message)
+		}
+		return nil, fmt.Errorf("JSON unmarshal failed: %v", err)
+	}
+	results := make(map[string]rpcResult, len(data))
+	for _, item := range data {
+		switch {
+		case item.Error != nil:
+			results[item.Id] = rpcResult{Err: &rpcError{Code: item.Error.Code, Message: item.Error.Message}}
+		case item.Result == nil:
+			results[item.Id] = rpcResult{Err: fmt.Errorf("result not found")}
+		default:
+			results[item.Id] = rpcResult{Result: *item.Result}
+		}
+	}
+	return results, nil
+}
+
+// 将十六进制余额转换为定点数金额
+func parseBalanceResult(result string, decimals uint8) (pkg.Amount, error) {
+	// eth_call 对非合约地址会返回 0x
+	if result == "0x" {
+		return pkg.Amount{}, fmt.Errorf("empty result, contract may not exist")
 	}
-	balance, err := pkg.ConvertBigIntToAmount(pkg.HexToBigInt(*data.Result), decimals)
-	if err == nil {
-		return balance, nil
+	value, ok := new(big.Int).SetString(strings.TrimPrefix(result, "0x"), 16)
+	if !ok {
+		// 如果解析失败，返回错误
+		return pkg.Amount{}, fmt.Errorf("parse hex error")
 	}
-	// 如果解析失败，返回错误
-	return 0, fmt.Errorf("parse hex error")
+	return pkg.NewAmount(value, decimals), nil
 }
@@ -0,0 +1,212 @@
+package core
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fuxingjun/balance-bot/pkg"
+	"github.com/gofiber/fiber/v2"
+)
+
+// --- 交易所适配器 ---
+
+// Exchange 交易所适配器, 每个交易所在单独的文件中实现一次, 并在 init 中注册适配器与各 host 的限速窗口
+// 监控能力以可选接口提供, 监控通过 exchangeCapability 查询交易所是否支持
+type Exchange interface {
+	// Name 交易所名称, 小写, 与提交交易对中的 exchange 对应
+	Name() string
+	// PerpSymbol 由 base 与 quote 拼出永续合约的原生 symbol, 如 BTC_USDT
+	PerpSymbol(base, quote string) string
+}
+
+// VolumeProvider 24h 成交额
+type VolumeProvider interface {
+	// Volumes 返回关注的 symbol 的 24h 成交额(美元)
+	Volumes(symbols []string) ([]PerpTicker, error)
+	// DefaultVolumeThreshold 默认 24h 成交额阈值(美元)
+	DefaultVolumeThreshold() float64
+}
+
+// FundingProvider 资金费率
+type FundingProvider interface {
+	// FundingRates 返回关注的 symbol 的资金费率与结算间隔
+	FundingRates(symbols []string) ([]FundingInfo, error)
+	// DefaultFundingThreshold 默认资金费率绝对值阈值
+	DefaultFundingThreshold() float64
+}
+
+// OpenInterestProvider 持仓量
+type OpenInterestProvider interface {
+	// OpenInterest 返回关注的 symbol 的持仓量(美元)
+	OpenInterest(symbols []string) ([]OpenInterest, error)
+	// DefaultOpenInterestThreshold 默认持仓量阈值(美元)
+	DefaultOpenInterestThreshold() float64
+}
+
+// IndexProvider 指数成份
+type IndexProvider interface {
+	// IndexConstituents 返回 symbol 对应指数的成份
+	IndexConstituents(symbol string) ([]IndexConstituent, error)
+}
+
+// ContractProvider 合约信息
+type ContractProvider interface {
+	// Contracts 返回关注的 symbol 的合约字段, 未上架的 symbol 不出现在结果中
+	Contracts(symbols []string) (map[string]contractFields, error)
+}
+
+// PriceSource 价格来源, DEX 等新来源同样实现 Exchange 与该接口并注册即可
+type PriceSource interface {
+	// Supports 是否支持该类型的腿, 类型即 SymbolInfo.Type, 如 perp、spot
+	Supports(legType string) bool
+	// Prices 批量查询 symbol 的价格, 查询不到的 symbol 不出现在结果中
+	Prices(symbols []string) (map[string]PriceQuote, error)
+}
+
+// 已注册的交易所, key 为 Name()
+var exchanges = map[string]Exchange{}
+
+// 注册交易所, 只在 init 中调用
+func registerExchange(exchange Exchange) {
+	exchanges[exchange.Name()] = exchange
+}
+
+// 查询交易所是否提供某项能力, 如 exchangeCapability[VolumeProvider]("gate")
+func exchangeCapability[T any](name string) (T, bool) {
+	exchange, exists := exchanges[strings.ToLower(name)]
+	if !exists {
+		return *new(T), false
+	}
+	capability, ok := exchange.(T)
+	return capability, ok
+}
+
+// 各监控项使用的能力, 用于提示提交的交易所缺少哪些监控
+var exchangeMonitors = []struct {
+	name     string
+	supports func(exchange string) bool
+}{
+	{"volume", func(e string) bool { _, ok := exchangeCapability[VolumeProvider](e); return ok }},
+	{"funding", func(e string) bool { _, ok := exchangeCapability[FundingProvider](e); return ok }},
+	{"open_interest", func(e string) bool { _, ok := exchangeCapability[OpenInterestProvider](e); return ok }},
+	{"index", func(e string) bool { _, ok := exchangeCapability[IndexProvider](e); return ok }},
+	{"contract", func(e string) bool { _, ok := exchangeCapability[ContractProvider](e); return ok }},
+	{"spread", func(e string) bool { _, ok := exchangeCapability[PriceSource](e); return ok }},
+}
+
+// 在 info 级别提示提交的交易所缺少的监控, 避免交易对被静默忽略
+func reportUnsupportedExchanges(symbolsByExchange map[string][]string) {
+	names := make([]string, 0, len(symbolsByExchange))
+	for exchange := range symbolsByExchange {
+		names = append(names, exchange)
+	}
+	sort.Strings(names)
+	var unsupported []string
+	for _, exchange := range names {
+		var missing []string
+		for _, monitor := range exchangeMonitors {
+			if !monitor.supports(exchange) {
+				missing = append(missing, monitor.name)
+			}
+		}
+		switch {
+		case len(missing) == len(exchangeMonitors):
+			unsupported = append(unsupported, exchange)
+		case len(missing) > 0:
+			pkg.GetLogger().Info("Submitted exchange lacks some monitors", "exchange", exchange, "missing", missing)
+		}
+	}
+	if len(unsupported) > 0 {
+		pkg.GetLogger().Info("Submitted exchanges have no monitors, their pairs are ignored", "exchanges", unsupported)
+	}
+}
+
+// 交易所 host 的限速器, 同一 host 的所有请求共用
+type hostLimiter struct {
+	exchange string
+	limiter  *pkg.RateLimiter
+}
+
+// 已注册的 host 限速器, key 为 host
+var hostLimiters = map[string]hostLimiter{}
+
+// 注册 host 的限速窗口, 只在交易所文件的 init 中调用
+func registerHostLimit(exchange, host string, limit int, window time.Duration) {
+	hostLimiters[host] = hostLimiter{exchange: exchange, limiter: pkg.NewRateLimiter(limit, window)}
+}
+
+// 限流响应未带 Retry-After 时的默认暂停时长
+const (
+	defaultBackoff429 = 10 * time.Second
+	defaultBackoff418 = 2 * time.Minute // binance 的 418 表示 IP 已被封禁, 最短 2 分钟
+)
+
+// 交易所公共接口的 GET 请求, weight 为请求权重(binance 按接口区分, 其它交易所为 1)
+// 请求前经过 host 的限速器, 收到 429/418 时按 Retry-After 暂停该 host 的所有请求
+func exchangeGet[T any](weight int, rawURL string, params map[string]any) (T, error) {
+	return exchangeGetWithHeaders[T](weight, rawURL, params, nil)
+}
+
+// 同 exchangeGet, 附带请求头, 用于需要 API key 的接口
+func exchangeGetWithHeaders[T any](weight int, rawURL string, params map[string]any, headers map[string]string) (T, error) {
+	host := ""
+	if u, err := url.Parse(rawURL); err == nil {
+		host = u.Host
+	}
+	limiter := hostLimiters[host].limiter
+	if err := limiter.Wait(weight); err != nil {
+		return *new(T), err
+	}
+	resp, err := pkg.SendGetRequestMarshal[T](pkg.GetHTTPClient(), rawURL, params, headers)
+	var statusErr *pkg.HTTPStatusError
+	if errors.As(err, &statusErr) && (statusErr.StatusCode == fiber.StatusTooManyRequests || statusErr.StatusCode == fiber.StatusTeapot) {
+		backoff := statusErr.RetryAfter
+		if backoff <= 0 {
+			backoff = defaultBackoff429
+			if statusErr.StatusCode == fiber.StatusTeapot {
+				backoff = defaultBackoff418
+			}
+		}
+		limiter.Backoff(backoff)
+		pkg.GetLogger().Warn("Exchange rate limited, backing off", "host", host, "status", statusErr.StatusCode, "backoff", backoff)
+	}
+	return resp, err
+}
+
+// RateLimitEntry host 限速器状态
+type RateLimitEntry struct {
+	Host     string `json:"host"`
+	Exchange string `json:"exchange"`
+	pkg.RateLimiterStats
+}
+
+// RateLimitStatus 查询各交易所 host 的限速器状态, 支持 exchange 过滤
+func RateLimitStatus(c *fiber.Ctx) error {
+	exchange := strings.ToLower(c.Query("exchange"))
+	result := make([]RateLimitEntry, 0, len(hostLimiters))
+	for host, item := range hostLimiters {
+		if exchange != "" && item.exchange != exchange {
+			continue
+		}
+		result = append(result, RateLimitEntry{Host: host, Exchange: item.exchange, RateLimiterStats: item.limiter.Stats()})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Host < result[j].Host
+	})
+	return c.JSON(fiber.Map{
+		"status": "ok",
+		"data":   result,
+	})
+}
+
+// 关注的 symbol 集合, 用于筛选全量接口的返回
+func symbolSet(symbols []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(symbols))
+	for _, symbol := range symbols {
+		set[symbol] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,318 @@
+package core
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/fuxingjun/balance-bot/internal/config"
+	"github.com/fuxingjun/balance-bot/pkg"
+)
+
+// --- Binance U 本位永续合约 ---
+
+func init() {
+	registerExchange(&binanceExchange{})
+	// U 本位合约 IP 限制 2400 权重/分钟, 各接口权重见 exchangeGet 调用处
+	registerHostLimit("binance", "fapi.binance.com", 2400, time.Minute)
+}
+
+type binanceExchange struct{}
+
+func (e *binanceExchange) Name() string {
+	return "binance"
+}
+
+func (e *binanceExchange) PerpSymbol(base, quote string) string {
+	return base + quote
+}
+
+type BinanceTickerResponse struct {
+	Symbol      string `json:"symbol"`
+	QuoteVolume string `json:"quoteVolume"` // 24h 成交额(USDT)
+}
+
+func (e *binanceExchange) Volumes(symbols []string) ([]PerpTicker, error) {
+	url := "https://fapi.binance.com/fapi/v1/ticker/24hr"
+	resp, err := exchangeGet[[]BinanceTickerResponse](40, url, nil) // 不带 symbol 权重 40
+	if err != nil {
+		pkg.GetLogger().Error("Failed to request binance symbols", "error", err)
+		return nil, err
+	}
+	// 筛选出关注的symbol, 阈值判断由 checkVolumeMonitor 统一处理
+	set := symbolSet(symbols)
+	var result []PerpTicker
+	for _, ticker := range resp {
+		if _, exists := set[ticker.Symbol]; exists {
+			result = append(result, PerpTicker{
+				symbol:    ticker.Symbol,
+				volume24h: ticker.QuoteVolume,
+			})
+		}
+	}
+	return result, nil
+}
+
+func (e *binanceExchange) DefaultVolumeThreshold() float64 {
+	return 5000000 // 默认500w
+}
+
+type BinancePremiumIndexResponse struct {
+	Symbol          string `json:"symbol"`
+	MarkPrice       string `json:"markPrice"`
+	LastFundingRate string `json:"lastFundingRate"`
+	NextFundingTime int64  `json:"nextFundingTime"`
+}
+
+func (e *binanceExchange) premiumIndex() ([]BinancePremiumIndexResponse, error) {
+	url := "https://fapi.binance.com/fapi/v1/premiumIndex"
+	resp, err := exchangeGet[[]BinancePremiumIndexResponse](10, url, nil) // 不带 symbol 权重 10
+	if err != nil {
+		pkg.GetLogger().Error("Failed to request binance premium index", "error", err)
+		return nil, err
+	}
+	return resp, nil
+}
+
+type BinanceFundingInfoResponse struct {
+	Symbol               string `json:"symbol"`
+	FundingIntervalHours int    `json:"fundingIntervalHours"`
+}
+
+// FundingRates premiumIndex 的 lastFundingRate 是下次结算按当前溢价估算的费率, binance 不单独提供预测费率
+// fundingInfo 只返回调整过参数的 symbol, 未返回的使用默认 8 小时结算间隔
+func (e *binanceExchange) FundingRates(symbols []string) ([]FundingInfo, error) {
+	resp, err := e.premiumIndex()
+	if err != nil {
+		return nil, err
+	}
+	intervals, err := exchangeGet[[]BinanceFundingInfoResponse](1, "https://fapi.binance.com/fapi/v1/fundingInfo", nil)
+	if err != nil {
+		pkg.GetLogger().Error("Failed to request binance funding info", "error", err)
+		return nil, err
+	}
+	intervalBySymbol := make(map[string]int, len(intervals))
+	for _, info := range intervals {
+		intervalBySymbol[info.Symbol] = info.FundingIntervalHours
+	}
+	set := symbolSet(symbols)
+	var result []FundingInfo
+	for _, index := range resp {
+		if _, exists := set[index.Symbol]; !exists {
+			continue
+		}
+		interval, exists := intervalBySymbol[index.Symbol]
+		if !exists || interval <= 0 {
+			interval = 8
+		}
+		result = append(result, FundingInfo{
+			symbol:        index.Symbol,
+			rate:          pkg.StringToFloat(index.LastFundingRate),
+			intervalHours: interval,
+		})
+	}
+	return result, nil
+}
+
+func (e *binanceExchange) DefaultFundingThreshold() float64 {
+	return 0.005 // 默认 0.5%
+}
+
+type BinanceOpenInterestResponse struct {
+	Symbol       string `json:"symbol"`
+	OpenInterest string `json:"openInterest"`
+}
+
+// OpenInterest openInterest 只能按 symbol 查询, 单位为币的数量, 乘以标记价格折算为美元
+func (e *binanceExchange) OpenInterest(symbols []string) ([]OpenInterest, error) {
+	resp, err := e.premiumIndex()
+	if err != nil {
+		return nil, err
+	}
+	markPrices := make(map[string]float64, len(resp))
+	for _, index := range resp {
+		markPrices[index.Symbol] = pkg.StringToFloat(index.MarkPrice)
+	}
+	var result []OpenInterest
+	for _, symbol := range symbols {
+		// 不是 U 本位永续合约的 symbol 直接跳过
+		markPrice, exists := markPrices[symbol]
+		if !exists {
+			continue
+		}
+		oi, err := exchangeGet[BinanceOpenInterestResponse](1, "https://fapi.binance.com/fapi/v1/openInterest", map[string]any{"symbol": symbol})
+		if err != nil {
+			pkg.GetLogger().Error("Failed to request binance open interest", "symbol", symbol, "error", err)
+			continue
+		}
+		result = append(result, OpenInterest{
+			symbol: symbol,
+			usd:    pkg.StringToFloat(oi.OpenInterest) * markPrice,
+		})
+	}
+	return result, nil
+}
+
+func (e *binanceExchange) DefaultOpenInterestThreshold() float64 {
+	return 2000000 // 默认200w
+}
+
+type BinanceIndexResponse struct {
+	Symbol       string `json:"symbol"`
+	Time         int64  `json:"time"`
+	Constituents []struct {
+		Exchange string `json:"exchange"`
+		Symbol   string `json:"symbol"`
+		Price    string `json:"price"`
+		Weight   string `json:"weight"`
+	} `json:"constituents"`
+}
+
+func (r BinanceIndexResponse) constituents() []IndexConstituent {
+	result := make([]IndexConstituent, 0, len(r.Constituents))
+	for _, c := range r.Constituents {
+		result = append(result, IndexConstituent{Exchange: c.Exchange, Symbol: c.Symbol, Price: c.Price, Weight: c.Weight})
+	}
+	return result
+}
+
+func (e *binanceExchange) IndexConstituents(symbol string) ([]IndexConstituent, error) {
+	url := fmt.Sprintf("https://fapi.binance.com/fapi/v1/constituents?symbol=%s", symbol)
+	resp, err := exchangeGet[BinanceIndexResponse](2, url, nil)
+	if err != nil {
+		pkg.GetLogger().Error("Failed to request binance index constituents", "symbol", symbol, "error", err)
+		return nil, err
+	}
+	return resp.constituents(), nil
+}
+
+type BinanceExchangeInfoResponse struct {
+	Symbols []struct {
+		Symbol       string `json:"symbol"`
+		Status       string `json:"status"` // TRADING、PENDING_TRADING、PRE_DELIVERING、DELIVERING、DELIVERED、PRE_SETTLE、SETTLING、CLOSE
+		ContractType string `json:"contractType"`
+		DeliveryDate int64  `json:"deliveryDate"`
+		Filters      []struct {
+			FilterType string `json:"filterType"`
+			MaxQty     string `json:"maxQty"`
+		} `json:"filters"`
+	} `json:"symbols"`
+}
+
+// Contracts 合约状态、交割时间与单笔下单数量上限(maxQty / marketMaxQty, 不是持仓上限)
+// 最大杠杆与持仓名义价值上限只能通过需要签名的 leverageBracket 接口获取, 配置了 exchangeApi.binance 时一并监控
+func (e *binanceExchange) Contracts(symbols []string) (map[string]contractFields, error) {
+	url := "https://fapi.binance.com/fapi/v1/exchangeInfo"
+	resp, err := exchangeGet[BinanceExchangeInfoResponse](1, url, nil)
+	if err != nil {
+		pkg.GetLogger().Error("Failed to request binance exchange info", "error", err)
+		return nil, err
+	}
+	set := symbolSet(symbols)
+	result := make(map[string]contractFields)
+	for _, info := range resp.Symbols {
+		if _, exists := set[info.Symbol]; !exists {
+			continue
+		}
+		fields := contractFields{
+			"status":       info.Status,
+			"contractType": info.ContractType,
+			"deliveryDate": formatContractTime(info.DeliveryDate / 1000),
+		}
+		for _, filter := range info.Filters {
+			switch filter.FilterType {
+			case "LOT_SIZE":
+				fields["maxQty"] = filter.MaxQty
+			case "MARKET_LOT_SIZE":
+				fields["marketMaxQty"] = filter.MaxQty
+			}
+		}
+		result[info.Symbol] = fields
+	}
+	if err := e.addLeverageBrackets(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+type BinanceLeverageBracketResponse struct {
+	Symbol   string `json:"symbol"`
+	Brackets []struct {
+		Bracket         int     `json:"bracket"`
+		InitialLeverage int     `json:"initialLeverage"` // 该档位的最大杠杆
+		NotionalCap     float64 `json:"notionalCap"`     // 该档位的持仓名义价值上限
+	} `json:"brackets"`
+}
+
+// 补充最大杠杆(第一档杠杆)与持仓名义价值上限(最后一档上限), 未配置 API 密钥时跳过
+// 查询失败时返回错误, 避免字段缺失被当作变化
+func (e *binanceExchange) addLeverageBrackets(fields map[string]contractFields) error {
+	cfg, err := config.LoadConfig()
+	if err != nil || cfg == nil {
+		return err
+	}
+	apiKey, exists := cfg.ExchangeAPI["binance"]
+	if !exists || len(fields) == 0 {
+		return nil
+	}
+	// USER_DATA 接口, 不带 symbol 权重 1
+	query := "timestamp=" + strconv.FormatInt(time.Now().UnixMilli(), 10)
+	mac := hmac.New(sha256.New, []byte(apiKey.Secret))
+	mac.Write([]byte(query))
+	url := "https://fapi.binance.com/fapi/v1/leverageBracket?" + query + "&signature=" + hex.EncodeToString(mac.Sum(nil))
+	resp, err := exchangeGetWithHeaders[[]BinanceLeverageBracketResponse](1, url, nil, map[string]string{"X-MBX-APIKEY": apiKey.Key})
+	if err != nil {
+		pkg.GetLogger().Error("Failed to request binance leverage brackets", "error", err)
+		return err
+	}
+	for _, item := range resp {
+		symbolFields, exists := fields[item.Symbol]
+		if !exists || len(item.Brackets) == 0 {
+			continue
+		}
+		maxLeverage, maxNotional := 0, 0.0
+		for _, bracket := range item.Brackets {
+			maxLeverage = max(maxLeverage, bracket.InitialLeverage)
+			maxNotional = max(maxNotional, bracket.NotionalCap)
+		}
+		symbolFields["maxLeverage"] = strconv.Itoa(maxLeverage)
+		symbolFields["maxNotional"] = strconv.FormatFloat(maxNotional, 'f', -1, 64)
+	}
+	return nil
+}
+
+func (e *binanceExchange) Supports(legType string) bool {
+	return legType == "perp"
+}
+
+type BinancePriceResponse struct {
+	Symbol string `json:"symbol"`
+	Price  string `json:"price"`
+}
+
+// Prices 最新成交价与 premiumIndex 中的标记价格
+func (e *binanceExchange) Prices(symbols []string) (map[string]PriceQuote, error) {
+	url := "https://fapi.binance.com/fapi/v2/ticker/price"
+	resp, err := exchangeGet[[]BinancePriceResponse](4, url, nil) // 不带 symbol 权重 4
+	if err != nil {
+		pkg.GetLogger().Error("Failed to request binance futures prices", "error", err)
+		return nil, err
+	}
+	index, err := e.premiumIndex()
+	if err != nil {
+		return nil, err
+	}
+	quotes := make(map[string]PriceQuote, len(resp))
+	for _, ticker := range resp {
+		quotes[ticker.Symbol] = PriceQuote{Last: pkg.StringToFloat(ticker.Price)}
+	}
+	for _, item := range index {
+		quote := quotes[item.Symbol]
+		quote.Mark = pkg.StringToFloat(item.MarkPrice)
+		quotes[item.Symbol] = quote
+	}
+	return filterQuotes(symbols, quotes), nil
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fuxingjun/balance-bot/pkg"
+)
+
+// --- Bitget USDT 永续合约 ---
+// 未实现 IndexProvider: bitget 没有公开的指数成份接口, 需求中 bitget 指数成份监控这一项尚未完成, 待确认数据来源后再补充
+// 在此之前开启指数成份监控时由 checkIndexComponentMonitor 输出警告, bitget 交易对不做指数成份检查
+
+func init() {
+	registerExchange(&bitgetExchange{})
+	// 行情接口 20r/s
+	registerHostLimit("bitget", "api.bitget.com", 20, time.Second)
+}
+
+type bitgetExchange struct{}
+
+func (e *bitgetExchange) Name() string {
+	return "bitget"
+}
+
+func (e *bitgetExchange) PerpSymbol(base, quote string) string {
+	return base + quote
+}
+
+type BitgetResponse[T any] struct {
+	Code string `json:"code"`
+	Msg  string `json:"msg"`
+	Data T      `json:"data"`
+}
+
+type BitgetTickerResponse struct {
+	Symbol      string `json:"symbol"`
+	QuoteVolume string `json:"quoteVolume"` // 24h 成交额(USDT)
+}
+
+func (e *bitgetExchange) Volumes(symbols []string) ([]PerpTicker, error) {
+	url := "https://api.bitget.com/api/v2/mix/market/tickers?productType=USDT-FUTURES"
+	resp, err := exchangeGet[BitgetResponse[[]BitgetTickerResponse]](1, url, nil)
+	if err == nil && resp.Code != "00000" {
+		err = fmt.Errorf("bitget error: %s %s", resp.Code, resp.Msg)
+	}
+	if err != nil {
+		pkg.GetLogger().Error("Failed to request bitget symbols", "error", err)
+		return nil, err
+	}
+	set := symbolSet(symbols)
+	var result []PerpTicker
+	for _, ticker := range resp.Data {
+		if _, exists := set[ticker.Symbol]; exists {
+			result = append(result, PerpTicker{
+				symbol:    ticker.Symbol,
+				volume24h: ticker.QuoteVolume,
+			})
+		}
+	}
+	return result, nil
+}
+
+func (e *bitgetExchange) DefaultVolumeThreshold() float64 {
+	return 1000000 // 默认100w
+}
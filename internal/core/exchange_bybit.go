@@ -0,0 +1,99 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fuxingjun/balance-bot/pkg"
+)
+
+// --- Bybit USDT 永续合约 ---
+
+func init() {
+	registerExchange(&bybitExchange{})
+	// 公共接口按 IP 限制 600r/5s
+	registerHostLimit("bybit", "api.bybit.com", 600, 5*time.Second)
+}
+
+type bybitExchange struct{}
+
+func (e *bybitExchange) Name() string {
+	return "bybit"
+}
+
+func (e *bybitExchange) PerpSymbol(base, quote string) string {
+	return base + quote
+}
+
+type BybitResponse[T any] struct {
+	RetCode int    `json:"retCode"`
+	RetMsg  string `json:"retMsg"`
+	Result  T      `json:"result"`
+}
+
+// bybit 的业务错误通过 retCode 返回, HTTP 状态码仍为 200
+func bybitGet[T any](url string) (T, error) {
+	resp, err := exchangeGet[BybitResponse[T]](1, url, nil)
+	if err != nil {
+		return *new(T), err
+	}
+	if resp.RetCode != 0 {
+		return *new(T), fmt.Errorf("bybit error: %d %s", resp.RetCode, resp.RetMsg)
+	}
+	return resp.Result, nil
+}
+
+type BybitTickerResponse struct {
+	List []struct {
+		Symbol      string `json:"symbol"`
+		Turnover24h string `json:"turnover24h"` // 24h 成交额(USDT)
+	} `json:"list"`
+}
+
+func (e *bybitExchange) Volumes(symbols []string) ([]PerpTicker, error) {
+	resp, err := bybitGet[BybitTickerResponse]("https://api.bybit.com/v5/market/tickers?category=linear")
+	if err != nil {
+		pkg.GetLogger().Error("Failed to request bybit symbols", "error", err)
+		return nil, err
+	}
+	set := symbolSet(symbols)
+	var result []PerpTicker
+	for _, ticker := range resp.List {
+		if _, exists := set[ticker.Symbol]; exists {
+			result = append(result, PerpTicker{
+				symbol:    ticker.Symbol,
+				volume24h: ticker.Turnover24h,
+			})
+		}
+	}
+	return result, nil
+}
+
+func (e *bybitExchange) DefaultVolumeThreshold() float64 {
+	return 2000000 // 默认200w
+}
+
+type BybitIndexResponse struct {
+	IndexName  string `json:"indexName"`
+	Components []struct {
+		Exchange string `json:"exchange"`
+		SpotPair string `json:"spotPair"`
+		Price    string `json:"price"`
+		Weight   string `json:"weight"`
+	} `json:"components"`
+}
+
+// IndexConstituents USDT 永续合约的指数名与 symbol 相同
+func (e *bybitExchange) IndexConstituents(symbol string) ([]IndexConstituent, error) {
+	url := fmt.Sprintf("https://api.bybit.com/v5/market/index-price-components?indexName=%s", symbol)
+	resp, err := bybitGet[BybitIndexResponse](url)
+	if err != nil {
+		pkg.GetLogger().Error("Failed to request bybit index components", "symbol", symbol, "error", err)
+		return nil, err
+	}
+	constituents := make([]IndexConstituent, 0, len(resp.Components))
+	for _, component := range resp.Components {
+		constituents = append(constituents, IndexConstituent{Exchange: component.Exchange, Symbol: component.SpotPair, Price: component.Price, Weight: component.Weight})
+	}
+	return constituents, nil
+}
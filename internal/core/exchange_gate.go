@@ -0,0 +1,227 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/fuxingjun/balance-bot/pkg"
+)
+
+// --- Gate USDT 永续合约 ---
+
+func init() {
+	registerExchange(&gateExchange{})
+	// 公共接口 200r/10s, 网页接口按同样的频率限制
+	registerHostLimit("gate", "api.gateio.ws", 200, 10*time.Second)
+	registerHostLimit("gate", "www.gate.com", 200, 10*time.Second)
+}
+
+type gateExchange struct{}
+
+func (e *gateExchange) Name() string {
+	return "gate"
+}
+
+func (e *gateExchange) PerpSymbol(base, quote string) string {
+	return base + "_" + quote
+}
+
+type GateTickerResponse struct {
+	Contract        string `json:"contract"`
+	Last            string `json:"last"`
+	MarkPrice       string `json:"mark_price"`
+	Volume24hSettle string `json:"volume_24h_settle"` // 24h 成交额(USDT)
+}
+
+func (e *gateExchange) tickers() ([]GateTickerResponse, error) {
+	url := "https://api.gateio.ws/api/v4/futures/usdt/tickers"
+	resp, err := exchangeGet[[]GateTickerResponse](1, url, nil)
+	if err != nil {
+		pkg.GetLogger().Error("Failed to request gate tickers", "error", err)
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (e *gateExchange) Volumes(symbols []string) ([]PerpTicker, error) {
+	resp, err := e.tickers()
+	if err != nil {
+		return nil, err
+	}
+	// 筛选出关注的symbol, 阈值判断由 checkVolumeMonitor 统一处理
+	set := symbolSet(symbols)
+	var result []PerpTicker
+	for _, ticker := range resp {
+		if _, exists := set[ticker.Contract]; exists {
+			result = append(result, PerpTicker{
+				symbol:    ticker.Contract,
+				volume24h: ticker.Volume24hSettle,
+			})
+		}
+	}
+	return result, nil
+}
+
+func (e *gateExchange) DefaultVolumeThreshold() float64 {
+	return 500000 // 默认50w
+}
+
+type GateContractResponse struct {
+	Name                  string `json:"name"`
+	FundingRate           string `json:"funding_rate"`
+	FundingRateIndicative string `json:"funding_rate_indicative"`
+	FundingInterval       int    `json:"funding_interval"` // 秒
+	MarkPrice             string `json:"mark_price"`
+	QuantoMultiplier      string `json:"quanto_multiplier"` // 每张合约对应的币数量
+	PositionSize          int64  `json:"position_size"`     // 当前总持仓, 单位张
+	Status                string `json:"status"`            // trading、delisting、delisted
+	InDelisting           bool   `json:"in_delisting"`
+	DelistingTime         int64  `json:"delisting_time"`
+	LeverageMax           string `json:"leverage_max"`
+	RiskLimitMax          string `json:"risk_limit_max"`
+	OrderSizeMax          int64  `json:"order_size_max"`
+}
+
+// 查询关注的合约信息, 资金费率、持仓量与合约状态共用
+func (e *gateExchange) contracts(symbols []string) ([]GateContractResponse, error) {
+	url := "https://api.gateio.ws/api/v4/futures/usdt/contracts"
+	resp, err := exchangeGet[[]GateContractResponse](1, url, nil)
+	if err != nil {
+		pkg.GetLogger().Error("Failed to request gate contracts", "error", err)
+		return nil, err
+	}
+	set := symbolSet(symbols)
+	var result []GateContractResponse
+	for _, contract := range resp {
+		if _, exists := set[contract.Name]; exists {
+			result = append(result, contract)
+		}
+	}
+	return result, nil
+}
+
+// FundingRates funding_rate_indicative 为预测费率
+func (e *gateExchange) FundingRates(symbols []string) ([]FundingInfo, error) {
+	contracts, err := e.contracts(symbols)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]FundingInfo, 0, len(contracts))
+	for _, contract := range contracts {
+		result = append(result, FundingInfo{
+			symbol:        contract.Name,
+			rate:          pkg.StringToFloat(contract.FundingRate),
+			predicted:     pkg.StringToFloat(contract.FundingRateIndicative),
+			hasPredicted:  contract.FundingRateIndicative != "",
+			intervalHours: contract.FundingInterval / 3600,
+		})
+	}
+	return result, nil
+}
+
+func (e *gateExchange) DefaultFundingThreshold() float64 {
+	return 0.005 // 默认 0.5%
+}
+
+// OpenInterest position_size 为合约张数, 乘以合约乘数与标记价格折算为美元
+func (e *gateExchange) OpenInterest(symbols []string) ([]OpenInterest, error) {
+	contracts, err := e.contracts(symbols)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]OpenInterest, 0, len(contracts))
+	for _, contract := range contracts {
+		result = append(result, OpenInterest{
+			symbol: contract.Name,
+			usd:    float64(contract.PositionSize) * pkg.StringToFloat(contract.QuantoMultiplier) * pkg.StringToFloat(contract.MarkPrice),
+		})
+	}
+	return result, nil
+}
+
+func (e *gateExchange) DefaultOpenInterestThreshold() float64 {
+	return 200000 // 默认20w
+}
+
+// Contracts 合约状态、最大杠杆与持仓上限
+func (e *gateExchange) Contracts(symbols []string) (map[string]contractFields, error) {
+	contracts, err := e.contracts(symbols)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]contractFields, len(contracts))
+	for _, contract := range contracts {
+		result[contract.Name] = contractFields{
+			"status":        contract.Status,
+			"inDelisting":   strconv.FormatBool(contract.InDelisting),
+			"delistingTime": formatContractTime(contract.DelistingTime),
+			"leverageMax":   contract.LeverageMax,
+			"riskLimitMax":  contract.RiskLimitMax,
+			"orderSizeMax":  strconv.FormatInt(contract.OrderSizeMax, 10),
+		}
+	}
+	return result, nil
+}
+
+//	type GateIndexResponse struct {
+//		Index        string `json:"index"`
+//		Constituents []struct {
+//			Exchange string   `json:"exchange"`
+//			Symbols  []string `json:"symbols"`
+//		} `json:"constituents"`
+//	}
+type GateIndexResponse struct {
+	Method  string `json:"method"`
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+	Data    struct {
+		Index        string `json:"index"`
+		Constituents []struct {
+			Symbol      string `json:"symbol"`
+			Exchange    string `json:"exchange"`
+			SourcePrice string `json:"source_price"`
+			Weight      string `json:"weight"`
+			Price       string `json:"price"`
+		} `json:"constituents"`
+	}
+}
+
+func (r GateIndexResponse) constituents() []IndexConstituent {
+	result := make([]IndexConstituent, 0, len(r.Data.Constituents))
+	for _, c := range r.Data.Constituents {
+		result = append(result, IndexConstituent{Exchange: c.Exchange, Symbol: c.Symbol, Price: c.Price, Weight: c.Weight})
+	}
+	return result
+}
+
+func (e *gateExchange) IndexConstituents(symbol string) ([]IndexConstituent, error) {
+	// url := fmt.Sprintf("https://api.gateio.ws/api/v4/futures/usdt/index_constituents/%s", symbol)
+	// api没有成份占比信息，改用网页接口
+	url := fmt.Sprintf("https://www.gate.com/apiw/v2/futures/common/index/breakdown?index=%s", symbol)
+	resp, err := exchangeGet[GateIndexResponse](1, url, nil)
+	if err != nil {
+		pkg.GetLogger().Error("Failed to request gate index constituents", "symbol", symbol, "error", err)
+		return nil, err
+	}
+	return resp.constituents(), nil
+}
+
+func (e *gateExchange) Supports(legType string) bool {
+	return legType == "perp"
+}
+
+func (e *gateExchange) Prices(symbols []string) (map[string]PriceQuote, error) {
+	resp, err := e.tickers()
+	if err != nil {
+		return nil, err
+	}
+	quotes := make(map[string]PriceQuote, len(resp))
+	for _, ticker := range resp {
+		quotes[ticker.Contract] = PriceQuote{
+			Last: pkg.StringToFloat(ticker.Last),
+			Mark: pkg.StringToFloat(ticker.MarkPrice),
+		}
+	}
+	return filterQuotes(symbols, quotes), nil
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fuxingjun/balance-bot/pkg"
+)
+
+// --- OKX USDT 永续合约 ---
+
+func init() {
+	registerExchange(&okxExchange{})
+	// 公共行情接口 20r/2s
+	registerHostLimit("okx", "www.okx.com", 20, 2*time.Second)
+}
+
+type okxExchange struct{}
+
+func (e *okxExchange) Name() string {
+	return "okx"
+}
+
+func (e *okxExchange) PerpSymbol(base, quote string) string {
+	return base + "-" + quote + "-SWAP"
+}
+
+type OkxResponse[T any] struct {
+	Code string `json:"code"`
+	Msg  string `json:"msg"`
+	Data T      `json:"data"`
+}
+
+// okx 的业务错误通过 code 返回, HTTP 状态码仍为 200
+func okxGet[T any](url string) (T, error) {
+	resp, err := exchangeGet[OkxResponse[T]](1, url, nil)
+	if err != nil {
+		return *new(T), err
+	}
+	if resp.Code != "0" {
+		return *new(T), fmt.Errorf("okx error: %s %s", resp.Code, resp.Msg)
+	}
+	return resp.Data, nil
+}
+
+type OkxTickerResponse struct {
+	InstID    string `json:"instId"`
+	Last      string `json:"last"`
+	VolCcy24h string `json:"volCcy24h"` // 永续合约为 24h 成交量, 单位为币
+}
+
+// Volumes 成交量(币)乘以最新价折算为美元
+func (e *okxExchange) Volumes(symbols []string) ([]PerpTicker, error) {
+	resp, err := okxGet[[]OkxTickerResponse]("https://www.okx.com/api/v5/market/tickers?instType=SWAP")
+	if err != nil {
+		pkg.GetLogger().Error("Failed to request okx symbols", "error", err)
+		return nil, err
+	}
+	set := symbolSet(symbols)
+	var result []PerpTicker
+	for _, ticker := range resp {
+		if _, exists := set[ticker.InstID]; exists {
+			volume := pkg.StringToFloat(ticker.VolCcy24h) * pkg.StringToFloat(ticker.Last)
+			result = append(result, PerpTicker{
+				symbol:    ticker.InstID,
+				volume24h: strconv.FormatFloat(volume, 'f', 2, 64),
+			})
+		}
+	}
+	return result, nil
+}
+
+func (e *okxExchange) DefaultVolumeThreshold() float64 {
+	return 2000000 // 默认200w
+}
+
+type OkxIndexResponse struct {
+	Index      string `json:"index"`
+	Components []struct {
+		Exch   string `json:"exch"`
+		Symbol string `json:"symbol"`
+		SymPx  string `json:"symPx"`
+		Wgt    string `json:"wgt"`
+	} `json:"components"`
+}
+
+// IndexConstituents 永续合约 BTC-USDT-SWAP 对应指数 BTC-USDT
+func (e *okxExchange) IndexConstituents(symbol string) ([]IndexConstituent, error) {
+	index := strings.TrimSuffix(symbol, "-SWAP")
+	url := fmt.Sprintf("https://www.okx.com/api/v5/market/index-components?index=%s", index)
+	resp, err := okxGet[OkxIndexResponse](url)
+	if err != nil {
+		pkg.GetLogger().Error("Failed to request okx index components", "symbol", symbol, "error", err)
+		return nil, err
+	}
+	constituents := make([]IndexConstituent, 0, len(resp.Components))
+	for _, component := range resp.Components {
+		constituents = append(constituents, IndexConstituent{Exchange: component.Exch, Symbol: component.Symbol, Price: component.SymPx, Weight: component.Wgt})
+	}
+	return constituents, nil
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fuxingjun/balance-bot/internal/config"
+	"github.com/fuxingjun/balance-bot/internal/utils"
+	"github.com/fuxingjun/balance-bot/pkg"
+)
+
+// --- 心跳内容断言: 收到心跳但 extra 中的指标不健康时告警 ---
+
+// 按服务配置的 rules 检查心跳 extra, 返回不满足的断言及原因
+func evaluateHealthRules(rules []string, extra json.RawMessage) []string {
+	if len(rules) == 0 {
+		return nil
+	}
+	if len(extra) == 0 || string(extra) == "null" {
+		return []string{"extra is missing"}
+	}
+	doc, err := pkg.DecodeJSON(extra)
+	if err != nil {
+		return []string{fmt.Sprintf("extra is not valid JSON: %v", err)}
+	}
+	var failures []string
+	for _, rule := range rules {
+		assertion, err := pkg.ParseAssertion(rule)
+		if err != nil {
+			// 配置加载时已校验, 这里只是防御
+			failures = append(failures, fmt.Sprintf("%s: %v", rule, err))
+			continue
+		}
+		if err := assertion.Check(doc); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", assertion.Expr, err))
+		}
+	}
+	return failures
+}
+
+// 根据断言结果触发或恢复告警, 去重与恢复语义与心跳超时一致: 单次异常最多通知 warnCount 次, 间隔不小于 interval
+// 暂停或维护窗口内只记录不告警
+func applyHealthRuleResult(name string, policy config.HealthServiceConfig, failures []string, suppressed bool) {
+	key := healthRuleAlertKey(name)
+	if len(failures) > 0 && suppressed {
+		pkg.GetLogger().Info("Health check unhealthy during maintenance", "service", name, "failures", failures)
+		return
+	}
+	if len(failures) > 0 {
+		alertPolicy := AlertPolicy{
+			Renotify:  time.Duration(policy.Interval) * time.Second,
+			MaxNotify: policy.WarnCount,
+		}
+		msg := fmt.Sprintf("⚠️ Health check unhealthy for %s:\n%s", name, strings.Join(failures, "\n"))
+		pkg.GetLogger().Warn(msg)
+		if !fireAlert(key, alertPolicy) {
+			return
+		}
+		go func() {
+			if err := utils.SendMessageTo(policy.Channels, msg); err != nil {
+				pkg.GetLogger().Error("Failed to send alert", "error", err, "service", name)
+			}
+		}()
+		return
+	}
+	if _, ok := resolveAlert(key); ok {
+		msg := fmt.Sprintf("✅ Health check for %s is healthy again", name)
+		pkg.GetLogger().Info(msg)
+		go func() {
+			if err := utils.SendMessageTo(policy.Channels, msg); err != nil {
+				pkg.GetLogger().Error("Failed to send recovery", "error", err, "service", name)
+			}
+		}()
+	}
+}
+
+// 心跳内容告警的唯一标识
+func healthRuleAlertKey(name string) string {
+	return "health-rules:" + name
+}
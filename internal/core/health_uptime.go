@@ -0,0 +1,111 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/fuxingjun/balance-bot/internal/config"
+)
+
+// --- 心跳中断记录与可用率统计 ---
+
+// 中断记录保留时长, 覆盖最长的 SLA 统计窗口
+const outageRetention = 7 * 24 * 3600
+
+// SLA 统计窗口
+var slaWindows = []struct {
+	name    string
+	seconds int
+}{
+	{"1d", 24 * 3600},
+	{"7d", 7 * 24 * 3600},
+}
+
+// Outage 一次心跳中断, 从超时时刻(最后心跳 + 超时周期)到恢复心跳
+type Outage struct {
+	Start       int  `json:"start"`
+	End         int  `json:"end"`
+	MissedBeats int  `json:"missedBeats"`       // 中断期间错过的心跳次数
+	FirstMissed int  `json:"firstMissed"`       // 第一次错过的心跳预期时间
+	LastMissed  int  `json:"lastMissed"`        // 最后一次错过的心跳预期时间
+	Planned     bool `json:"planned,omitempty"` // 恢复时处于暂停或维护窗口, 不计入 SLA
+}
+
+// 根据最后一次心跳与恢复时间计算中断信息, 未超时返回 false
+func newOutage(lastBeat, now int, policy config.HealthServiceConfig) (Outage, bool) {
+	start := lastBeat + policy.Timeout()
+	if policy.Interval <= 0 || now <= start {
+		return Outage{}, false
+	}
+	// 预期在 lastBeat + k*interval 收到心跳, 统计 (lastBeat, now) 内的预期次数
+	missed := (now - lastBeat - 1) / policy.Interval
+	outage := Outage{Start: start, End: now, MissedBeats: missed}
+	if missed > 0 {
+		outage.FirstMissed = lastBeat + policy.Interval
+		outage.LastMissed = lastBeat + missed*policy.Interval
+	}
+	return outage, true
+}
+
+// 记录中断并清理超出保留时长的记录, 调用方需持有 storeMutex
+func (s *HealthStatus) recordOutage(outage Outage) {
+	s.Outages = append(s.Outages, outage)
+	cutoff := outage.End - outageRetention
+	kept := s.Outages[:0]
+	for _, o := range s.Outages {
+		if o.End > cutoff {
+			kept = append(kept, o)
+		}
+	}
+	s.Outages = kept
+}
+
+// 计算 [now-window, now] 内的可用率(百分比), 包含进行中的中断, 调用方需持有 storeMutex
+func (s *HealthStatus) availability(policy config.HealthServiceConfig, window, now int, suppressed bool) float64 {
+	from := max(now-window, s.RegisteredAt)
+	if now <= from {
+		return 100
+	}
+	outages := s.Outages
+	if ongoing, ok := newOutage(s.LastHeartbeat, now, policy); ok {
+		ongoing.Planned = suppressed
+		outages = append(outages[:len(outages):len(outages)], ongoing)
+	}
+	down := 0
+	for _, o := range outages {
+		if o.Planned {
+			continue
+		}
+		down += max(min(o.End, now)-max(o.Start, from), 0)
+	}
+	ratio := 100 * (1 - float64(down)/float64(now-from))
+	return math.Round(ratio*1000) / 1000
+}
+
+// 各统计窗口的可用率, 调用方需持有 storeMutex
+func (s *HealthStatus) sla(name string, cfg *config.AppConfig, now int) map[string]float64 {
+	policy := s.policy(cfg, name)
+	suppressed := healthSuppressedUntil(name, s, now) > 0
+	result := make(map[string]float64, len(slaWindows))
+	for _, window := range slaWindows {
+		result[window.name] = s.availability(policy, window.seconds, now, suppressed)
+	}
+	return result
+}
+
+// 恢复通知内容
+func formatRecoveryMessage(name string, outage Outage) string {
+	msg := fmt.Sprintf("✅ Health check recovered for %s, heartbeat received at %s\nOutage: %s (since %s)",
+		name, formatTime(outage.End), formatDuration(outage.End-outage.Start), formatTime(outage.Start))
+	if outage.MissedBeats > 0 {
+		msg += fmt.Sprintf("\nMissed beats: %d (first %s, last %s)",
+			outage.MissedBeats, formatTime(outage.FirstMissed), formatTime(outage.LastMissed))
+	}
+	return msg
+}
+
+// 格式化时长, 如 1h5m30s
+func formatDuration(seconds int) string {
+	return (time.Duration(seconds) * time.Second).String()
+}
@@ -14,15 +14,27 @@ import (
 )
 
 type HealthStatus struct {
-	LastHeartbeat int
-	NotifyTask    *time.Timer
-	WarnCount     int  // 添加警告计数
-	IsAlerting    bool // 添加告警状态标识
+	LastHeartbeat int                        `json:"lastHeartbeat"`
+	NotifyTask    *time.Timer                `json:"-"`
+	WarnCount     int                        `json:"warnCount"`              // 添加警告计数
+	IsAlerting    bool                       `json:"isAlerting"`             // 添加告警状态标识
+	RegisteredAt  int                        `json:"registeredAt,omitempty"` // 首次心跳时间, 秒数
+	Outages       []Outage                   `json:"outages,omitempty"`      // 最近 7 天的中断记录
+	PausedUntil   int                        `json:"pausedUntil,omitempty"`  // 暂停告警截止时间, 秒数
+	RuleFailures  []string                   `json:"ruleFailures,omitempty"` // 最近一次心跳未满足的断言
+	Declared      config.HealthServiceConfig `json:"declared,omitzero"`      // 服务在心跳中声明的策略
+}
+
+// 服务生效的心跳策略, 每次使用时按当前配置计算, 热加载后立即生效
+func (s *HealthStatus) policy(cfg *config.AppConfig, name string) config.HealthServiceConfig {
+	return cfg.HealthCheck.Policy(name, s.Declared)
 }
 
 type HealthPayload struct {
 	Name  string          `json:"name"` // 服务名称, 可用来区分不同服务
 	Extra json.RawMessage `json:"extra,omitempty"`
+	// 服务声明的心跳策略, 均允许为空; services 中的配置优先
+	config.HealthServiceConfig
 }
 
 var (
@@ -55,6 +67,19 @@ func HealthCheck(c *fiber.Ctx) error {
 			"error": "name is required",
 		})
 	}
+	// 严格模式下只接受配置过的服务, 避免服务名拼写错误导致永远收不到告警
+	if cfg.HealthCheck.Strict && !cfg.HealthCheck.Known(payload.Name) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "unknown service: " + payload.Name,
+		})
+	}
+	// 断言规则只能在配置中设置, 忽略服务自行声明的规则
+	payload.Rules = nil
+	if err := payload.HealthServiceConfig.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
 	// 记录心跳时间, 秒数
 	now := int(time.Now().Unix())
 	// 如果不存在则初始化
@@ -66,8 +91,10 @@ func HealthCheck(c *fiber.Ctx) error {
 		// 初始化新的健康状态
 		status = &HealthStatus{
 			LastHeartbeat: now,
+			RegisteredAt:  now,
 			WarnCount:     0,
 			IsAlerting:    false,
+			Declared:      payload.HealthServiceConfig,
 		}
 		healthStore[payload.Name] = status
 	} else {
@@ -77,28 +104,61 @@ func HealthCheck(c *fiber.Ctx) error {
 			status.NotifyTask = nil
 		}
 
-		// 重置告警状态
+		// 超过超时周期才收到心跳, 记录中断; 恢复时处于暂停或维护窗口的中断不计入 SLA
+		outage, down := newOutage(status.LastHeartbeat, now, status.policy(cfg, payload.Name))
+		if down {
+			outage.Planned = healthSuppressedUntil(payload.Name, status, now) > 0
+			status.recordOutage(outage)
+		}
+
+		// 重置告警状态, 服务可以在每次心跳中更新声明的策略
 		status.LastHeartbeat = now
 		status.WarnCount = 0
 		status.IsAlerting = false
+		status.Declared = payload.HealthServiceConfig
+
+		// 之前处于告警, 发送带中断详情的恢复通知
+		if _, ok := resolveAlert(healthAlertKey(payload.Name)); ok {
+			msg := fmt.Sprintf("✅ Health check recovered for %s, heartbeat received at %s", payload.Name, formatTime(now))
+			if down {
+				msg = formatRecoveryMessage(payload.Name, outage)
+			}
+			pkg.GetLogger().Info(msg)
+			channels := status.policy(cfg, payload.Name).Channels
+			go func() {
+				if err := utils.SendMessageTo(channels, msg); err != nil {
+					pkg.GetLogger().Error("Failed to send recovery", "error", err, "service", payload.Name)
+				}
+			}()
+		}
 	}
 
+	// 检查心跳内容
+	policy := status.policy(cfg, payload.Name)
+	status.RuleFailures = evaluateHealthRules(policy.Rules, payload.Extra)
+	suppressed := healthSuppressedUntil(payload.Name, status, now) > 0
+	applyHealthRuleResult(payload.Name, policy, status.RuleFailures, suppressed)
+
+	saveState(stateBucketHealth, payload.Name, status)
+
 	// 设置新的告警任务
-	status.NotifyTask = time.AfterFunc(time.Duration(cfg.HealthCheck.Interval)*time.Second, func() {
-		handleHealthCheckTimeout(payload.Name, status, cfg)
+	status.NotifyTask = time.AfterFunc(time.Duration(policy.Timeout())*time.Second, func() {
+		handleHealthCheckTimeout(payload.Name, status)
 	})
 
 	return c.JSON(fiber.Map{
 		"status": "ok",
 		"data": fiber.Map{
 			"lastHeartbeat": formatTime(status.LastHeartbeat),
-			"nextCheck":     formatTime(status.LastHeartbeat + cfg.HealthCheck.Interval),
+			"nextCheck":     formatTime(status.LastHeartbeat + policy.Timeout()),
+			"policy":        policy,
+			"ruleFailures":  status.RuleFailures,
 		},
 	})
 }
 
-// 处理超时告警的独立函数
-func handleHealthCheckTimeout(name string, status *HealthStatus, cfg *config.AppConfig) {
+// 处理超时告警的独立函数, 每轮读取当前配置, 热加载后立即使用新的间隔与次数
+func handleHealthCheckTimeout(name string, status *HealthStatus) {
 	storeMutex.Lock()
 	if status.IsAlerting {
 		storeMutex.Unlock()
@@ -107,19 +167,45 @@ func handleHealthCheckTimeout(name string, status *HealthStatus, cfg *config.App
 	status.IsAlerting = true
 	storeMutex.Unlock()
 
-	for i := 0; i < cfg.HealthCheck.WarnCount; i++ {
-		storeMutex.RLock()
+	for i := 0; ; i++ {
+		cfg, err := config.LoadConfig()
+		if err != nil || cfg == nil {
+			break
+		}
+		now := int(time.Now().Unix())
+		storeMutex.Lock()
+		// 服务已注销
+		if healthStore[name] != status {
+			storeMutex.Unlock()
+			break
+		}
 		lastBeat := status.LastHeartbeat
-		storeMutex.RUnlock()
+		policy := status.policy(cfg, name)
+		// 暂停或维护窗口内不告警, 结束后再给服务一个超时周期恢复心跳
+		if until := healthSuppressedUntil(name, status, now); until > 0 {
+			status.NotifyTask = time.AfterFunc(time.Duration(until-now+policy.Timeout())*time.Second, func() {
+				handleHealthCheckTimeout(name, status)
+			})
+			storeMutex.Unlock()
+			pkg.GetLogger().Info("Health check alert suppressed", "service", name, "until", formatTime(until))
+			break
+		}
+		storeMutex.Unlock()
+		if i >= policy.WarnCount {
+			break
+		}
 		msg := fmt.Sprintf("⚠️ Health check timeout for %s, last heartbeat at %s", name, formatTime(lastBeat))
 
 		pkg.GetLogger().Warn(msg)
 
-		if err := utils.SendMessage(msg); err != nil {
-			pkg.GetLogger().Error("Failed to send alert", "error", err, "service", name, "attempt", i+1)
+		// 同一次超时最多通知 WarnCount 次
+		if fireAlert(healthAlertKey(name), AlertPolicy{MaxNotify: policy.WarnCount}) {
+			if err := utils.SendMessageTo(policy.Channels, msg); err != nil {
+				pkg.GetLogger().Error("Failed to send alert", "error", err, "service", name, "attempt", i+1)
+			}
 		}
 
-		time.Sleep(time.Duration(cfg.HealthCheck.Interval) * time.Second)
+		time.Sleep(time.Duration(policy.Interval) * time.Second)
 
 		// 检查是否已经收到新的心跳
 		storeMutex.RLock()
@@ -135,6 +221,130 @@ func handleHealthCheckTimeout(name string, status *HealthStatus, cfg *config.App
 	storeMutex.Unlock()
 }
 
+// DeregisterHealth 注销服务, 停止监控并清除告警, 之后再收到心跳会重新注册
+func DeregisterHealth(c *fiber.Ctx) error {
+	name := c.Params("name")
+	storeMutex.Lock()
+	status, exists := healthStore[name]
+	if exists {
+		if status.NotifyTask != nil {
+			status.NotifyTask.Stop()
+			status.NotifyTask = nil
+		}
+		delete(healthStore, name)
+	}
+	storeMutex.Unlock()
+	if !exists {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "service not found: " + name,
+		})
+	}
+	resolveAlert(healthAlertKey(name))
+	resolveAlert(healthRuleAlertKey(name))
+	deleteState(stateBucketHealth, name)
+	pkg.GetLogger().Info("Health check service deregistered", "service", name)
+	return c.JSON(fiber.Map{
+		"status": "ok",
+	})
+}
+
+type PausePayload struct {
+	Duration string `json:"duration"` // 暂停时长, 如 30m、2h
+}
+
+// PauseHealth 暂停服务的告警一段时间, 暂停期间仍然接收心跳
+func PauseHealth(c *fiber.Ctx) error {
+	name := c.Params("name")
+	var payload PausePayload
+	if err := c.BodyParser(&payload); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid payload",
+		})
+	}
+	duration, err := time.ParseDuration(payload.Duration)
+	if err != nil || duration <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "duration must be a positive duration such as 30m or 2h",
+		})
+	}
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+	status, exists := healthStore[name]
+	if !exists {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "service not found: " + name,
+		})
+	}
+	status.PausedUntil = int(time.Now().Add(duration).Unix())
+	saveState(stateBucketHealth, name, status)
+	pkg.GetLogger().Info("Health check service paused", "service", name, "until", formatTime(status.PausedUntil))
+	return c.JSON(fiber.Map{
+		"status": "ok",
+		"data": fiber.Map{
+			"pausedUntil": formatTime(status.PausedUntil),
+		},
+	})
+}
+
+// ResumeHealth 取消暂停, 已经超时的服务会在一个超时周期后告警
+func ResumeHealth(c *fiber.Ctx) error {
+	cfg, err := config.LoadConfig()
+	if err != nil || cfg == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to load config",
+		})
+	}
+	name := c.Params("name")
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+	status, exists := healthStore[name]
+	if !exists {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "service not found: " + name,
+		})
+	}
+	status.PausedUntil = 0
+	saveState(stateBucketHealth, name, status)
+	if !status.IsAlerting {
+		if status.NotifyTask != nil {
+			status.NotifyTask.Stop()
+		}
+		now := int(time.Now().Unix())
+		policy := status.policy(cfg, name)
+		delay := max(status.LastHeartbeat+policy.Timeout()-now, policy.Timeout())
+		status.NotifyTask = time.AfterFunc(time.Duration(delay)*time.Second, func() {
+			handleHealthCheckTimeout(name, status)
+		})
+	}
+	pkg.GetLogger().Info("Health check service resumed", "service", name)
+	return c.JSON(fiber.Map{
+		"status": "ok",
+	})
+}
+
+// 心跳策略变更后按新策略重设所有未告警服务的超时任务
+func rescheduleHealthTimers(cfg *config.AppConfig) {
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+	now := int(time.Now().Unix())
+	for name, status := range healthStore {
+		if status.IsAlerting || status.NotifyTask == nil {
+			continue
+		}
+		status.NotifyTask.Stop()
+		policy := status.policy(cfg, name)
+		delay := max(status.LastHeartbeat+policy.Timeout()-now, 0)
+		status.NotifyTask = time.AfterFunc(time.Duration(delay)*time.Second, func() {
+			handleHealthCheckTimeout(name, status)
+		})
+	}
+}
+
+// 健康检测告警的唯一标识
+func healthAlertKey(name string) string {
+	return "health:" + name
+}
+
 // 格式化时间带时区
 func formatTime(t int) string {
 	return time.Unix(int64(t), 0).Format(time.RFC3339)
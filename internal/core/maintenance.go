@@ -0,0 +1,124 @@
+package core
+
+import (
+	"sort"
+	"time"
+
+	"github.com/fuxingjun/balance-bot/internal/config"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// --- 维护窗口与暂停: 窗口内不发送告警 ---
+
+// 匹配目标且正在生效的维护窗口, 返回最晚的结束时间
+func maintenanceUntil(now time.Time, targets ...string) (time.Time, bool) {
+	cfg, err := config.LoadConfig()
+	if err != nil || cfg == nil {
+		return time.Time{}, false
+	}
+	var until time.Time
+	for i := range cfg.Maintenance {
+		window := &cfg.Maintenance[i]
+		if !window.Matches(targets...) {
+			continue
+		}
+		if end, active := window.ActiveAt(now); active && end.After(until) {
+			until = end
+		}
+	}
+	return until, !until.IsZero()
+}
+
+// 告警是否因维护窗口被抑制
+func inMaintenance(targets ...string) bool {
+	_, ok := maintenanceUntil(time.Now(), targets...)
+	return ok
+}
+
+// 服务的告警抑制截止时间(秒), 取暂停与维护窗口中较晚者, 未抑制时返回 0, 调用方需持有 storeMutex
+func healthSuppressedUntil(name string, status *HealthStatus, now int) int {
+	until := 0
+	if status.PausedUntil > now {
+		until = status.PausedUntil
+	}
+	if end, ok := maintenanceUntil(time.Unix(int64(now), 0), name); ok {
+		until = max(until, int(end.Unix()))
+	}
+	return until
+}
+
+type MaintenanceEntry struct {
+	Name      string   `json:"name"`
+	Schedule  string   `json:"schedule"` // cron 表达式或一次性开始时间
+	Duration  int      `json:"duration"`
+	Targets   []string `json:"targets"`
+	Active    bool     `json:"active"`
+	ActiveEnd string   `json:"activeEnd,omitempty"` // 生效中窗口的结束时间
+	NextStart string   `json:"nextStart,omitempty"`
+}
+
+type PausedService struct {
+	Name        string `json:"name"`
+	PausedUntil string `json:"pausedUntil"`
+}
+
+// MaintenanceStatus 查询维护窗口与暂停中的服务, active=true 只返回生效中的窗口
+func MaintenanceStatus(c *fiber.Ctx) error {
+	cfg, err := config.LoadConfig()
+	if err != nil || cfg == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to load config",
+		})
+	}
+	onlyActive := c.QueryBool("active")
+	now := time.Now()
+	windows := make([]MaintenanceEntry, 0, len(cfg.Maintenance))
+	for i := range cfg.Maintenance {
+		window := &cfg.Maintenance[i]
+		entry := MaintenanceEntry{
+			Name:     window.Name,
+			Schedule: window.Cron,
+			Duration: window.Duration,
+			Targets:  window.Targets,
+		}
+		if window.Start != "" {
+			entry.Schedule = window.Start
+		}
+		if entry.Targets == nil {
+			entry.Targets = []string{"*"}
+		}
+		if end, active := window.ActiveAt(now); active {
+			entry.Active = true
+			entry.ActiveEnd = end.Format(time.RFC3339)
+		}
+		if next, ok := window.NextStart(now); ok {
+			entry.NextStart = next.Format(time.RFC3339)
+		}
+		if onlyActive && !entry.Active {
+			continue
+		}
+		windows = append(windows, entry)
+	}
+
+	nowSec := int(now.Unix())
+	storeMutex.RLock()
+	paused := make([]PausedService, 0)
+	for name, status := range healthStore {
+		if status.PausedUntil > nowSec {
+			paused = append(paused, PausedService{Name: name, PausedUntil: formatTime(status.PausedUntil)})
+		}
+	}
+	storeMutex.RUnlock()
+	sort.Slice(paused, func(i, j int) bool {
+		return paused[i].Name < paused[j].Name
+	})
+
+	return c.JSON(fiber.Map{
+		"status": "ok",
+		"data": fiber.Map{
+			"windows": windows,
+			"paused":  paused,
+		},
+	})
+}
@@ -0,0 +1,35 @@
+package core
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// healthCollector 在抓取时计算每个服务距上次心跳的秒数
+type healthCollector struct {
+	desc *prometheus.Desc
+}
+
+var heartbeatAgeDesc = prometheus.NewDesc(
+	"balance_bot_health_last_heartbeat_age_seconds",
+	"Seconds since the last heartbeat by service name.",
+	[]string{"name"}, nil,
+)
+
+func (c *healthCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *healthCollector) Collect(ch chan<- prometheus.Metric) {
+	storeMutex.RLock()
+	defer storeMutex.RUnlock()
+	now := time.Now().Unix()
+	for name, status := range healthStore {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(now-int64(status.LastHeartbeat)), name)
+	}
+}
+
+func init() {
+	prometheus.MustRegister(&healthCollector{desc: heartbeatAgeDesc})
+}
@@ -5,6 +5,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/fuxingjun/balance-bot/internal/config"
 	"github.com/fuxingjun/balance-bot/internal/utils"
 	"github.com/fuxingjun/balance-bot/pkg"
 	"github.com/gofiber/fiber/v2"
@@ -38,21 +39,32 @@ func PairsMonitor(c *fiber.Ctx) error {
 	}
 	pkg.GetLogger().Debug("Pairs monitor received", "pairs", pairs)
 
-	// 收集交易所对应的symbol, symbol 注意去重
+	// 收集交易所对应的symbol, symbol 注意去重; 永续合约单独收集, 用于资金费率监控
 	symbolsByExchange := make(map[string][]string)
+	perpsByExchange := make(map[string][]string)
+	for i := range pairs {
+		// 各交易所 symbol 格式不同, 统一转为交易所的原生格式
+		pairs[i].A.Symbol = normalizeSymbol(pairs[i].A.Exchange, pairs[i].A.Type, pairs[i].A.Symbol)
+		pairs[i].B.Symbol = normalizeSymbol(pairs[i].B.Exchange, pairs[i].B.Type, pairs[i].B.Symbol)
+	}
 	for _, pair := range pairs {
-		// 增加非空校验和统一转小写
-		if pair.A.Exchange != "" && pair.A.Symbol != "" {
-			exchange := strings.ToLower(pair.A.Exchange)
-			symbolsByExchange[exchange] = append(symbolsByExchange[exchange], pair.A.Symbol)
-		}
-
-		if pair.B.Exchange != "" && pair.B.Symbol != "" {
-			exchange := strings.ToLower(pair.B.Exchange)
-			symbolsByExchange[exchange] = append(symbolsByExchange[exchange], pair.B.Symbol)
+		for _, leg := range []SymbolInfo{pair.A, pair.B} {
+			// 增加非空校验和统一转小写
+			if leg.Exchange == "" || leg.Symbol == "" {
+				continue
+			}
+			exchange := strings.ToLower(leg.Exchange)
+			symbolsByExchange[exchange] = append(symbolsByExchange[exchange], leg.Symbol)
+			if strings.EqualFold(leg.Type, "perp") {
+				perpsByExchange[exchange] = append(perpsByExchange[exchange], leg.Symbol)
+			}
 		}
 	}
 
+	// 缓存交易对, 后台价差监控使用
+	storePairs(pairs)
+	reportUnsupportedExchanges(symbolsByExchange)
+
 	// 去重, 并且请求各个交易所的数据
 	for exchange, symbols := range symbolsByExchange {
 		// 这里的 symbols 是局部变量，直接去重即可
@@ -60,7 +72,7 @@ func PairsMonitor(c *fiber.Ctx) error {
 		// 缓存symbols, 后台循环监控使用
 		symbolsCache.Set(exchange, uniqueSymbols)
 		// 启动协程处理
-		go checkExchangeSymbol(exchange, uniqueSymbols)
+		go checkExchangeSymbol(exchange, uniqueSymbols, utils.RemoveDuplicates(perpsByExchange[exchange]))
 	}
 
 	return c.JSON(fiber.Map{
@@ -69,22 +81,30 @@ func PairsMonitor(c *fiber.Ctx) error {
 	})
 }
 
-// 24小时缓存
-// 注意：这个变量在同一个包(core)下的 monitor_volume.go 中也会被用到
+// 24小时缓存, 记录各告警 24 小时内的通知次数
+// 注意：这个变量在同一个包(core)下的 monitor_volume.go 与 monitor_funding.go 中也会被用到
 var notifyCache = pkg.NewTTLCache(24 * 3600 * 1e9)
 
-// checkExchangeSymbol 统一入口，分发到各个具体的监控项
-func checkExchangeSymbol(exchange string, symbols []string) {
+// checkExchangeSymbol 统一入口，分发到各个具体的监控项, perps 为其中的永续合约
+func checkExchangeSymbol(exchange string, symbols []string, perps []string) {
 	pkg.GetLogger().Debug("Requesting exchange data", "exchange", exchange, "symbols", symbols)
 	// 1. 交易量监控 (实现位于 monitor_volume.go)
 	go checkVolumeMonitor(exchange, symbols)
+	// 2. 资金费率监控, 只检查永续合约 (实现位于 monitor_funding.go)
+	if len(perps) > 0 {
+		go checkFundingMonitor(exchange, perps)
+	}
 }
 
-// 后台持续监控指数成份
+// 后台持续监控指数成份, 每轮检查配置开关, 支持热加载启停
 func StartIndexMonitor() {
 	pkg.GetLogger().Info("Starting index monitor...")
 	// 所有交易所并行, 等待所有交易所完成3S之后再开始下一轮
 	for {
+		if cfg, err := config.LoadConfig(); err != nil || cfg == nil || !cfg.IndexComponentMonitor {
+			time.Sleep(3 * time.Second)
+			continue
+		}
 		var wg sync.WaitGroup
 		cacheKeyList := symbolsCache.GetAllKeys()
 		for exchange, symList := range cacheKeyList {
@@ -98,3 +118,47 @@ func StartIndexMonitor() {
 		time.Sleep(3 * time.Second)
 	}
 }
+
+// 后台持续监控合约状态, 每轮检查配置开关, 支持热加载启停
+func StartContractStatusMonitor() {
+	pkg.GetLogger().Info("Starting contract status monitor...")
+	// 所有交易所并行, 合约信息接口均为全量返回, 每轮间隔 10S
+	for {
+		if cfg, err := config.LoadConfig(); err != nil || cfg == nil || !cfg.ContractStatusMonitor {
+			time.Sleep(3 * time.Second)
+			continue
+		}
+		var wg sync.WaitGroup
+		for exchange, symList := range symbolsCache.GetAllKeys() {
+			wg.Add(1)
+			go func(exch string, symbols []string) {
+				defer wg.Done()
+				checkContractStatusMonitor(exch, symbols)
+			}(exchange, symList)
+		}
+		wg.Wait()
+		time.Sleep(10 * time.Second)
+	}
+}
+
+// 后台持续监控持仓量, 复用 symbolsCache 中的 symbol, 每轮检查配置开关, 支持热加载启停与调整间隔
+func StartOpenInterestMonitor() {
+	pkg.GetLogger().Info("Starting open interest monitor...")
+	for {
+		cfg, err := config.LoadConfig()
+		if err != nil || cfg == nil || !cfg.OpenInterestMonitor.Enabled {
+			time.Sleep(3 * time.Second)
+			continue
+		}
+		var wg sync.WaitGroup
+		for exchange, symList := range symbolsCache.GetAllKeys() {
+			wg.Add(1)
+			go func(exch string, symbols []string) {
+				defer wg.Done()
+				checkOpenInterestMonitor(exch, symbols, cfg.OpenInterestMonitor)
+			}(exchange, symList)
+		}
+		wg.Wait()
+		time.Sleep(time.Duration(cfg.OpenInterestMonitor.Interval) * time.Second)
+	}
+}
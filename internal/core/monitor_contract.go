@@ -0,0 +1,86 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fuxingjun/balance-bot/internal/metrics"
+	"github.com/fuxingjun/balance-bot/internal/utils"
+	"github.com/fuxingjun/balance-bot/pkg"
+)
+
+// --- 合约状态监控相关 ---
+
+// 合约的关注字段, 如 status、leverageMax, 值统一转为字符串便于比较
+type contractFields map[string]string
+
+// 合约字段基线, key 为 exchange_contract_symbol
+var contractCache = pkg.NewSimpleCache(nil)
+
+func checkContractStatusMonitor(exchange string, symbols []string) {
+	provider, exists := exchangeCapability[ContractProvider](exchange)
+	if !exists {
+		pkg.GetLogger().Debug("Unsupported exchange for contract status monitor", "exchange", exchange)
+		return
+	}
+	start := time.Now()
+	contracts, err := provider.Contracts(symbols)
+	metrics.MonitorRunDuration.WithLabelValues("contract", exchange).Observe(time.Since(start).Seconds())
+	if err != nil {
+		pkg.GetLogger().Debug("Failed to get contract info", "exchange", exchange, "error", err)
+		return
+	}
+
+	for _, symbol := range symbols {
+		fields, listed := contracts[symbol]
+		if !listed {
+			// 从接口中消失视为已下架
+			fields = contractFields{"listed": "false"}
+		} else {
+			fields["listed"] = "true"
+		}
+		// 如果基线已经存在且不同，则说明合约信息有变化
+		cacheKey := exchange + "_contract_" + symbol
+		if cached, exists := contractCache.Get(cacheKey); exists {
+			if changes := diffContractFields(cached.(contractFields), fields); len(changes) > 0 {
+				msg := fmt.Sprintf("Contract info changed on %s for %s:\n%s", exchange, symbol, strings.Join(changes, "\n"))
+				pkg.GetLogger().Warn(msg)
+				// 发送报警通知
+				utils.SendMessage(msg)
+			} else {
+				pkg.GetLogger().Debug("No change in contract info", "exchange", exchange, "symbol", symbol)
+				continue
+			}
+		}
+		// 更新基线
+		contractCache.Set(cacheKey, fields)
+	}
+}
+
+// 按字段名排序列出变化, 旧基线中没有的字段(如新增关注字段)不告警
+func diffContractFields(old, current contractFields) []string {
+	names := make([]string, 0, len(current))
+	for name := range current {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var changes []string
+	for _, name := range names {
+		before, exists := old[name]
+		if !exists || before == current[name] {
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("  - %s: %s -> %s", name, before, current[name]))
+	}
+	return changes
+}
+
+// 秒级时间戳转为可读时间, 0 表示未设置
+func formatContractTime(ts int64) string {
+	if ts <= 0 {
+		return "-"
+	}
+	return time.Unix(ts, 0).Format(time.RFC3339)
+}
@@ -0,0 +1,152 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fuxingjun/balance-bot/internal/config"
+	"github.com/fuxingjun/balance-bot/internal/metrics"
+	"github.com/fuxingjun/balance-bot/internal/utils"
+	"github.com/fuxingjun/balance-bot/pkg"
+)
+
+// --- 资金费率监控相关 ---
+
+type FundingInfo struct {
+	symbol        string
+	rate          float64 // 当前资金费率
+	predicted     float64 // 预测的下期资金费率
+	hasPredicted  bool    // 交易所是否提供预测费率
+	intervalHours int     // 结算间隔(小时)
+}
+
+// 各 symbol 上次看到的结算间隔(小时), key 为 exchange_symbol
+var fundingIntervalCache = pkg.NewSimpleCache(nil)
+
+func checkFundingMonitor(exchange string, symbols []string) {
+	exchange = strings.ToLower(exchange)
+	provider, exists := exchangeCapability[FundingProvider](exchange)
+	if !exists {
+		pkg.GetLogger().Debug("Unsupported exchange for funding monitor", "exchange", exchange)
+		return
+	}
+
+	start := time.Now()
+	infos, err := provider.FundingRates(symbols)
+	metrics.MonitorRunDuration.WithLabelValues("funding", exchange).Observe(time.Since(start).Seconds())
+	if err != nil {
+		pkg.GetLogger().Debug("Failed to get funding rates", "exchange", exchange, "error", err)
+		return
+	}
+
+	var msgParts, intervalParts, recoveredParts []string
+	notifyCount := getFundingNotifyCount()
+	threshold, predictedThreshold := getFundingThresholds(exchange, provider)
+	policy := defaultAlertPolicy()
+
+	for _, info := range infos {
+		// 1. 结算间隔变化, 每次变化都是独立事件, 只受 24h 通知次数限制
+		intervalKey := exchange + "_" + info.symbol
+		if val, exists := fundingIntervalCache.Get(intervalKey); exists {
+			if prev := val.(int); prev != info.intervalHours && info.intervalHours > 0 {
+				if allowNotify("funding_interval:"+intervalKey, notifyCount) {
+					intervalParts = append(intervalParts, fmt.Sprintf("symbol: %s, funding interval: %dh -> %dh", info.symbol, prev, info.intervalHours))
+				}
+			}
+		}
+		if info.intervalHours > 0 {
+			fundingIntervalCache.Set(intervalKey, info.intervalHours)
+		}
+
+		// 2. 资金费率超出阈值
+		cacheKey := "funding:" + intervalKey
+		high := math.Abs(info.rate) >= threshold
+		predictedHigh := info.hasPredicted && math.Abs(info.predicted) >= predictedThreshold
+		detail := "symbol: " + info.symbol + ", " + formatFunding(info)
+		if notify, recovered := evaluateAlert(cacheKey, high || predictedHigh, policy, notifyCount); recovered {
+			recoveredParts = append(recoveredParts, detail)
+		} else if notify {
+			msgParts = append(msgParts, detail)
+		}
+	}
+
+	if len(msgParts) > 0 {
+		msg := fmt.Sprintf("Funding rate too high on %s (threshold %s, predicted %s):\n%s",
+			exchange, formatRate(threshold), formatRate(predictedThreshold), strings.Join(msgParts, "\n"))
+		pkg.GetLogger().Info("Sending funding alert", "exchange", exchange, "message", msg)
+		utils.SendMessage(msg)
+	}
+	if len(intervalParts) > 0 {
+		msg := "Funding interval changed on " + exchange + ":\n" + strings.Join(intervalParts, "\n")
+		pkg.GetLogger().Info("Sending funding interval alert", "exchange", exchange, "message", msg)
+		utils.SendMessage(msg)
+	}
+	if len(recoveredParts) > 0 {
+		msg := "✅ Funding rate back to normal on " + exchange + ":\n" + strings.Join(recoveredParts, "\n")
+		pkg.GetLogger().Info("Sending funding recovery", "exchange", exchange, "message", msg)
+		utils.SendMessage(msg)
+	}
+}
+
+// 保护 notifyCache 的读取与计数, 多个监控并发检查同一个 key 时不会超出上限
+var notifyMutex sync.Mutex
+
+// 24 小时内的通知次数未达上限时计数并返回 true
+func allowNotify(cacheKey string, notifyCount int) bool {
+	notifyMutex.Lock()
+	defer notifyMutex.Unlock()
+	count := 0
+	if val, exists := notifyCache.Get(cacheKey); exists {
+		count = val.(int)
+	}
+	if count >= notifyCount {
+		return false
+	}
+	notifyCache.Set(cacheKey, count+1)
+	return true
+}
+
+func formatFunding(info FundingInfo) string {
+	text := "funding rate: " + formatRate(info.rate)
+	if info.hasPredicted {
+		text += ", predicted: " + formatRate(info.predicted)
+	}
+	return text + fmt.Sprintf(", interval: %dh", info.intervalHours)
+}
+
+// 资金费率按百分比展示
+func formatRate(rate float64) string {
+	return fmt.Sprintf("%.4f%%", rate*100)
+}
+
+func getFundingNotifyCount() int {
+	cfg, err := config.LoadConfig()
+	if err != nil || cfg == nil {
+		return 3
+	}
+	return cfg.FundingMonitor.NotifyCount
+}
+
+// 获取交易所的资金费率阈值, 未配置时使用默认值, 预测费率阈值默认同当前费率阈值
+func getFundingThresholds(exchange string, provider FundingProvider) (float64, float64) {
+	threshold := provider.DefaultFundingThreshold()
+	predicted := 0.0
+	if cfg, err := config.LoadConfig(); err == nil && cfg != nil {
+		for _, platform := range cfg.FundingMonitor.Platform {
+			if !strings.EqualFold(platform.Platform, exchange) {
+				continue
+			}
+			if platform.Threshold > 0 {
+				threshold = platform.Threshold
+			}
+			predicted = platform.PredictedThreshold
+		}
+	}
+	if predicted <= 0 {
+		predicted = threshold
+	}
+	return threshold, predicted
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/fuxingjun/balance-bot/internal/metrics"
 	"github.com/fuxingjun/balance-bot/internal/utils"
 	"github.com/fuxingjun/balance-bot/pkg"
 )
@@ -13,176 +15,81 @@ import (
 // --- 合约指数成份监控相关 ---
 
 func checkIndexComponentMonitor(exchange string, symbols []string) {
-	checker, exists := indexComponentCheckers[exchange]
+	provider, exists := exchangeCapability[IndexProvider](exchange)
 	if !exists {
-		pkg.GetLogger().Debug("Unsupported exchange for index component monitor", "exchange", exchange)
+		// 如 bitget 没有公开的指数成份接口, 每个交易所只提示一次, 避免每轮刷屏
+		if _, warned := indexUnsupportedWarned.LoadOrStore(exchange, true); !warned {
+			pkg.GetLogger().Warn("Index component monitor is enabled but exchange does not support it, its symbols are skipped", "exchange", exchange, "symbols", symbols)
+		}
 		return
 	}
-	checker(symbols)
-}
-
-// 交易所方法配置映射
-var indexComponentCheckers = map[string]func([]string){
-	// 预留接口：后期实现各交易所的成份检查函数
-	"binance": checkBinanceIndexComponents,
-	"gate":    checkGateIndexComponents,
-}
-
-// 指数成份不限时缓存
-var indexCache = pkg.NewSimpleCache(nil)
-
-type BinanceIndexResponse struct {
-	Symbol       string `json:"symbol"`
-	Time         int64  `json:"time"`
-	Constituents []struct {
-		Exchange string `json:"exchange"`
-		Symbol   string `json:"symbol"`
-		Price    string `json:"price"`
-		Weight   string `json:"weight"`
-	} `json:"constituents"`
-}
-
-func formatBinanceConstituents(c BinanceIndexResponse) string {
-	// 创建副本以避免修改原始数据的顺序
-	constituents := make([]struct {
-		Exchange string `json:"exchange"`
-		Symbol   string `json:"symbol"`
-		Price    string `json:"price"`
-		Weight   string `json:"weight"`
-	}, len(c.Constituents))
-	copy(constituents, c.Constituents)
-
-	// 按权重降序排序
-	sort.Slice(constituents, func(i, j int) bool {
-		w1 := pkg.StringToFloat(constituents[i].Weight)
-		w2 := pkg.StringToFloat(constituents[j].Weight)
-		return w1 > w2
-	})
-
-	var parts []string
-	for _, constituent := range constituents {
-		// 使用换行和缩进，使每个成分独占一行，格式更清晰
-		parts = append(parts, fmt.Sprintf("  - %s: %s (Weight: %s)", constituent.Exchange, constituent.Symbol, constituent.Weight))
-	}
-	return strings.Join(parts, "\n")
-}
-
-func checkBinanceIndexComponents(symbols []string) {
-	pkg.GetLogger().Debug("Checking Binance index components", "symbols", symbols)
+	pkg.GetLogger().Debug("Checking index components", "exchange", exchange, "symbols", symbols)
+	start := time.Now()
+	// 逐个 symbol 查询, 限速由交易所适配器处理
 	for _, symbol := range symbols {
-		url := fmt.Sprintf("https://fapi.binance.com/fapi/v1/constituents?symbol=%s", symbol)
-		resp, err := pkg.SendGetRequestMarshal[BinanceIndexResponse](pkg.GetHTTPClient(), url, nil, nil)
+		constituents, err := provider.IndexConstituents(symbol)
 		if err != nil {
-			pkg.GetLogger().Error("Failed to request binance symbols", "error", err)
 			continue
 		}
-		// 如果缓存已经存在且不同，则说明成份有变化
-		cacheKey := "binance_index_" + symbol
-		if cached, exists := indexCache.Get(cacheKey); exists {
-			cachedResp := cached.(BinanceIndexResponse)
-
-			// 使用格式化后的字符串进行比较，可以忽略原始列表的顺序差异
-			oldStr := formatBinanceConstituents(cachedResp)
-			newStr := formatBinanceConstituents(resp)
-
-			if oldStr != newStr {
-				// 调整消息格式以适应多行显示
-				msg := fmt.Sprintf("Binance index constituents changed for %s:\n[Old]:\n%s\n\n[New]:\n%s", symbol, oldStr, newStr)
-				pkg.GetLogger().Warn(msg)
-				// 发送报警通知
-				utils.SendMessage(msg)
-			} else {
-				pkg.GetLogger().Debug("No change in Binance index constituents", "symbol", symbol)
-			}
-		}
-		// 更新缓存
-		indexCache.Set(cacheKey, resp)
-		// 防止接口限速(公共接口1200r/分钟)
-		time.Sleep(60 * time.Millisecond)
+		compareIndexConstituents(exchange, symbol, constituents)
 	}
+	metrics.MonitorRunDuration.WithLabelValues("index", exchange).Observe(time.Since(start).Seconds())
 }
 
-//	type GateIndexResponse struct {
-//		Index        string `json:"index"`
-//		Constituents []struct {
-//			Exchange string   `json:"exchange"`
-//			Symbols  []string `json:"symbols"`
-//		} `json:"constituents"`
-//	}
-type GateIndexResponse struct {
-	Method  string `json:"method"`
-	Message string `json:"message"`
-	Code    int    `json:"code"`
-	Data    struct {
-		Index        string `json:"index"`
-		Constituents []struct {
-			Symbol      string `json:"symbol"`
-			Exchange    string `json:"exchange"`
-			SourcePrice string `json:"source_price"`
-			Weight      string `json:"weight"`
-			Price       string `json:"price"`
-		} `json:"constituents"`
-	}
-}
+// 已提示过不支持指数成份监控的交易所
+var indexUnsupportedWarned sync.Map
+
+// 指数成份不限时缓存, key 为 exchange_index_symbol
+var indexCache = pkg.NewSimpleCache(nil)
 
-func formatGateConstituents(c GateIndexResponse) string {
+func formatConstituents(constituents []IndexConstituent) string {
 	// 创建副本以避免修改原始数据的顺序
-	constituents := make([]struct {
-		Symbol      string `json:"symbol"`
-		Exchange    string `json:"exchange"`
-		SourcePrice string `json:"source_price"`
-		Weight      string `json:"weight"`
-		Price       string `json:"price"`
-	}, len(c.Data.Constituents))
-	copy(constituents, c.Data.Constituents)
+	sorted := make([]IndexConstituent, len(constituents))
+	copy(sorted, constituents)
 
 	// 按权重降序排序
-	sort.Slice(constituents, func(i, j int) bool {
-		w1 := pkg.StringToFloat(constituents[i].Weight)
-		w2 := pkg.StringToFloat(constituents[j].Weight)
-		return w1 > w2
+	sort.Slice(sorted, func(i, j int) bool {
+		return pkg.StringToFloat(sorted[i].Weight) > pkg.StringToFloat(sorted[j].Weight)
 	})
 
 	var parts []string
-	for _, constituent := range constituents {
+	for _, constituent := range sorted {
 		// 使用换行和缩进，使每个成分独占一行，格式更清晰
 		parts = append(parts, fmt.Sprintf("  - %s: %s (Weight: %s)", constituent.Exchange, constituent.Symbol, constituent.Weight))
 	}
 	return strings.Join(parts, "\n")
 }
 
-// checkGateIndexComponents 检查 Gate 指数成份是否有变化
-func checkGateIndexComponents(symbols []string) {
-	pkg.GetLogger().Debug("Checking Gate index components", "symbols", symbols)
-	for _, symbol := range symbols {
-		// url := fmt.Sprintf("https://api.gateio.ws/api/v4/futures/usdt/index_constituents/%s", symbol)
-		// api没有成份占比信息，改用网页接口
-		url := fmt.Sprintf("https://www.gate.com/apiw/v2/futures/common/index/breakdown?index=%s", symbol)
-		resp, err := pkg.SendGetRequestMarshal[GateIndexResponse](pkg.GetHTTPClient(), url, nil, nil)
-		if err != nil {
-			pkg.GetLogger().Error("Failed to request gate symbols", "error", err)
+// 与缓存中的成份比较, 有变化时告警, 之后更新缓存
+// 成份价格每轮都会变化, 成份与权重不变时只更新内存, 不写入状态存储
+func compareIndexConstituents(exchange, symbol string, constituents []IndexConstituent) {
+	cacheKey := exchange + "_index_" + symbol
+	if cached, exists := indexCache.Get(cacheKey); exists {
+		// 使用格式化后的字符串进行比较，可以忽略原始列表的顺序差异
+		oldStr := formatConstituents(cached.([]IndexConstituent))
+		newStr := formatConstituents(constituents)
+		if oldStr == newStr {
+			pkg.GetLogger().Debug("No change in index constituents", "exchange", exchange, "symbol", symbol)
+			indexCache.SetWithoutPersist(cacheKey, constituents)
+			return
 		}
-		// 如果缓存已经存在且不同，则说明成份有变化
-		cacheKey := "gate_index_" + symbol
-		if cached, exists := indexCache.Get(cacheKey); exists {
-			cachedResp := cached.(GateIndexResponse)
-			// 使用格式化后的字符串进行比较，可以忽略原始列表的顺序差异
-			oldStr := formatGateConstituents(cachedResp)
-			newStr := formatGateConstituents(resp)
+		// 调整消息格式以适应多行显示
+		msg := fmt.Sprintf("%s index constituents changed for %s:\n[Old]:\n%s\n\n[New]:\n%s", exchangeTitle(exchange), symbol, oldStr, newStr)
+		pkg.GetLogger().Warn(msg)
+		// 发送报警通知
+		utils.SendMessage(msg)
+	}
+	// 更新缓存
+	indexCache.Set(cacheKey, constituents)
+}
 
-			if oldStr != newStr {
-				// 调整消息格式以适应多行显示
-				msg := fmt.Sprintf("Gate index constituents changed for %s:\n[Old]:\n%s\n\n[New]:\n%s", symbol, oldStr, newStr)
-				pkg.GetLogger().Warn(msg)
-				// 发送报警通知
-				utils.SendMessage(msg)
-			} else {
-				pkg.GetLogger().Debug("No change in Gate index constituents", "symbol", symbol)
-			}
-		}
-		// 更新缓存
-		indexCache.Set(cacheKey, resp)
-		// 防止接口限速(公共接口单个接口 200r/10s)
-		time.Sleep(60 * time.Millisecond)
+// 交易所名称首字母大写, 用于通知展示
+func exchangeTitle(exchange string) string {
+	switch exchange {
+	case "okx":
+		return "OKX"
+	case "":
+		return ""
 	}
+	return strings.ToUpper(exchange[:1]) + exchange[1:]
 }
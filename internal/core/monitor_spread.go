@@ -0,0 +1,223 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fuxingjun/balance-bot/internal/config"
+	"github.com/fuxingjun/balance-bot/internal/metrics"
+	"github.com/fuxingjun/balance-bot/internal/utils"
+	"github.com/fuxingjun/balance-bot/pkg"
+)
+
+// --- 两腿价差监控相关 ---
+
+// PriceQuote 一个 symbol 的最新成交价与标记价格, 不提供标记价格的来源 Mark 为 0
+type PriceQuote struct {
+	Last float64
+	Mark float64
+}
+
+// 最近一次提交的交易对, key 为 pairKey
+var pairsCache = pkg.NewSimpleCache(nil)
+
+// 各交易对上次超过 flipMinSpread 时的价差方向(1 或 -1), key 为 pairKey
+var spreadSignCache = pkg.NewSimpleCache(nil)
+
+// 交易对缓存 key, 优先使用提交的 id
+func pairKey(pair PairInfo) string {
+	if pair.ID != "" {
+		return pair.ID
+	}
+	return legLabel(pair.A) + "/" + legLabel(pair.B)
+}
+
+func legLabel(leg SymbolInfo) string {
+	return strings.ToLower(leg.Exchange) + ":" + leg.Symbol
+}
+
+// 用最新提交的交易对替换缓存, 只删除不再提交的交易对, 未变化的交易对不会重复写入存储
+func storePairs(pairs []PairInfo) {
+	submitted := make(map[string]PairInfo, len(pairs))
+	for _, pair := range pairs {
+		if pair.A.Exchange == "" || pair.A.Symbol == "" || pair.B.Exchange == "" || pair.B.Symbol == "" {
+			continue
+		}
+		submitted[pairKey(pair)] = pair
+	}
+	for key := range pairsCache.Items() {
+		if _, exists := submitted[key]; !exists {
+			pairsCache.Delete(key)
+		}
+	}
+	for key, pair := range submitted {
+		pairsCache.Set(key, pair)
+	}
+}
+
+// 后台持续监控两腿价差, 每轮检查配置开关, 支持热加载启停与调整间隔
+func StartSpreadMonitor() {
+	pkg.GetLogger().Info("Starting spread monitor...")
+	for {
+		cfg, err := config.LoadConfig()
+		if err != nil || cfg == nil || !cfg.SpreadMonitor.Enabled {
+			time.Sleep(3 * time.Second)
+			continue
+		}
+		checkSpreadMonitor(cfg.SpreadMonitor)
+		time.Sleep(time.Duration(cfg.SpreadMonitor.Interval) * time.Second)
+	}
+}
+
+func checkSpreadMonitor(cfg config.SpreadMonitorConfig) {
+	pairs := make(map[string]PairInfo)
+	for key, value := range pairsCache.Items() {
+		if pair, ok := value.(PairInfo); ok {
+			pairs[key] = pair
+		}
+	}
+	// 已移除的交易对不再保留价差方向
+	for key := range spreadSignCache.Items() {
+		if _, exists := pairs[key]; !exists {
+			spreadSignCache.Delete(key)
+		}
+	}
+	if len(pairs) == 0 {
+		return
+	}
+
+	quotes := fetchLegPrices(pairs)
+
+	keys := make([]string, 0, len(pairs))
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var msgParts, flipParts, recoveredParts []string
+	policy := defaultAlertPolicy()
+	for _, key := range keys {
+		pair := pairs[key]
+		quoteA, okA := quotes[legLabel(pair.A)]
+		quoteB, okB := quotes[legLabel(pair.B)]
+		if !okA || !okB {
+			pkg.GetLogger().Debug("Missing price for pair", "pair", key, "a", legLabel(pair.A), "b", legLabel(pair.B))
+			continue
+		}
+		priceA, priceB := pickPrice(quoteA, cfg.PriceType), pickPrice(quoteB, cfg.PriceType)
+		if priceA <= 0 || priceB <= 0 {
+			continue
+		}
+		spread := (priceA - priceB) / priceB
+		detail := formatSpread(key, pair, priceA, priceB, spread)
+
+		// 1. 价差方向反转, 低于 flipMinSpread 的价差视为噪声, 不更新方向
+		if math.Abs(spread) >= cfg.FlipMinSpread {
+			sign := 1
+			if spread < 0 {
+				sign = -1
+			}
+			if val, exists := spreadSignCache.Get(key); exists && val.(int) != sign {
+				if allowNotify("spread_flip:"+key, cfg.NotifyCount) {
+					flipParts = append(flipParts, detail)
+				}
+			}
+			spreadSignCache.Set(key, sign)
+		}
+
+		// 2. 价差超出阈值
+		if notify, recovered := evaluateAlert("spread:"+key, math.Abs(spread) >= cfg.Threshold, policy, cfg.NotifyCount); recovered {
+			recoveredParts = append(recoveredParts, detail)
+		} else if notify {
+			msgParts = append(msgParts, detail)
+		}
+	}
+
+	if len(msgParts) > 0 {
+		msg := fmt.Sprintf("Spread too wide (threshold %s, %s price):\n%s",
+			formatRate(cfg.Threshold), cfg.PriceType, strings.Join(msgParts, "\n"))
+		pkg.GetLogger().Info("Sending spread alert", "message", msg)
+		utils.SendMessage(msg)
+	}
+	if len(flipParts) > 0 {
+		msg := "Spread flipped sign:\n" + strings.Join(flipParts, "\n")
+		pkg.GetLogger().Info("Sending spread flip alert", "message", msg)
+		utils.SendMessage(msg)
+	}
+	if len(recoveredParts) > 0 {
+		msg := "✅ Spread back to normal:\n" + strings.Join(recoveredParts, "\n")
+		pkg.GetLogger().Info("Sending spread recovery", "message", msg)
+		utils.SendMessage(msg)
+	}
+}
+
+// 按交易所并行查询所有腿的价格, 返回 key 为 legLabel 的报价
+func fetchLegPrices(pairs map[string]PairInfo) map[string]PriceQuote {
+	symbolsByExchange := make(map[string][]string)
+	for _, pair := range pairs {
+		for _, leg := range []SymbolInfo{pair.A, pair.B} {
+			exchange := strings.ToLower(leg.Exchange)
+			source, exists := exchangeCapability[PriceSource](exchange)
+			if !exists || !source.Supports(strings.ToLower(leg.Type)) {
+				pkg.GetLogger().Debug("Unsupported leg for spread monitor", "exchange", exchange, "type", leg.Type, "symbol", leg.Symbol)
+				continue
+			}
+			symbolsByExchange[exchange] = append(symbolsByExchange[exchange], leg.Symbol)
+		}
+	}
+
+	result := make(map[string]PriceQuote)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for exchange, symbols := range symbolsByExchange {
+		wg.Add(1)
+		go func(exchange string, symbols []string) {
+			defer wg.Done()
+			start := time.Now()
+			source, _ := exchangeCapability[PriceSource](exchange)
+			prices, err := source.Prices(utils.RemoveDuplicates(symbols))
+			metrics.MonitorRunDuration.WithLabelValues("spread", exchange).Observe(time.Since(start).Seconds())
+			if err != nil {
+				pkg.GetLogger().Debug("Failed to get prices", "exchange", exchange, "error", err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for symbol, quote := range prices {
+				result[exchange+":"+symbol] = quote
+			}
+		}(exchange, symbols)
+	}
+	wg.Wait()
+	return result
+}
+
+// 按配置选择价格, 来源不提供标记价格时退回最新成交价
+func pickPrice(quote PriceQuote, priceType string) float64 {
+	if priceType == "mark" && quote.Mark > 0 {
+		return quote.Mark
+	}
+	return quote.Last
+}
+
+func formatSpread(key string, pair PairInfo, priceA, priceB, spread float64) string {
+	return fmt.Sprintf("pair: %s, a: %s %s, b: %s %s, spread: %+.4f%%",
+		key, legLabel(pair.A), strconv.FormatFloat(priceA, 'f', -1, 64),
+		legLabel(pair.B), strconv.FormatFloat(priceB, 'f', -1, 64), spread*100)
+}
+
+// 只保留关注的 symbol
+func filterQuotes(symbols []string, quotes map[string]PriceQuote) map[string]PriceQuote {
+	result := make(map[string]PriceQuote, len(symbols))
+	for _, symbol := range symbols {
+		if quote, exists := quotes[symbol]; exists {
+			result[symbol] = quote
+		}
+	}
+	return result
+}
@@ -1,9 +1,12 @@
 package core
 
 import (
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/fuxingjun/balance-bot/internal/config"
+	"github.com/fuxingjun/balance-bot/internal/metrics"
 	"github.com/fuxingjun/balance-bot/internal/utils"
 	"github.com/fuxingjun/balance-bot/pkg"
 )
@@ -15,23 +18,17 @@ type PerpTicker struct {
 	volume24h string
 }
 
-// 定义交易所检查函数的类型
-type volumeChecker func([]string) ([]PerpTicker, error)
-
-// 交易所方法配置映射
-var volumeCheckers = map[string]volumeChecker{
-	"gate":    checkGateVolume,
-	"binance": checkBinanceVolume,
-}
-
 func checkVolumeMonitor(exchange string, symbols []string) {
-	checker, exists := volumeCheckers[strings.ToLower(exchange)]
+	exchange = strings.ToLower(exchange)
+	provider, exists := exchangeCapability[VolumeProvider](exchange)
 	if !exists {
 		pkg.GetLogger().Debug("Unsupported exchange for volume monitor", "exchange", exchange)
 		return
 	}
 
-	tickers, err := checker(symbols)
+	start := time.Now()
+	tickers, err := provider.Volumes(symbols)
+	metrics.MonitorRunDuration.WithLabelValues("volume", exchange).Observe(time.Since(start).Seconds())
 	if err != nil {
 		pkg.GetLogger().Debug("Failed to get tickers", "exchange", exchange, "error", err)
 		return
@@ -41,25 +38,21 @@ func checkVolumeMonitor(exchange string, symbols []string) {
 		return
 	}
 
-	var msgParts []string
+	var msgParts, recoveredParts []string
 	notifyCount := getNotifyCount()
+	thresholdUSD := getVolumeThreshold(exchange, provider)
+	policy := defaultAlertPolicy()
 
 	for _, ticker := range tickers {
 		// 增加前缀区分不同监控类型的缓存
-		cacheKey := "vol:" + strings.ToLower(exchange) + "_" + ticker.symbol
-		count := 0
-		if val, exists := notifyCache.Get(cacheKey); exists {
-			count = val.(int)
+		cacheKey := "vol:" + exchange + "_" + ticker.symbol
+		detail := "symbol: " + ticker.symbol + ", 24h volume: " + ticker.volume24h
+		low := pkg.StringToFloat(ticker.volume24h) < thresholdUSD
+		if notify, recovered := evaluateAlert(cacheKey, low, policy, notifyCount); recovered {
+			recoveredParts = append(recoveredParts, detail)
+		} else if notify {
+			msgParts = append(msgParts, detail)
 		}
-
-		if count >= notifyCount {
-			pkg.GetLogger().Debug("Skipping notification for", "symbol", ticker.symbol, "count", count)
-			continue
-		}
-
-		count++
-		notifyCache.Set(cacheKey, count)
-		msgParts = append(msgParts, "symbol: "+ticker.symbol+", 24h volume: "+ticker.volume24h)
 	}
 
 	if len(msgParts) > 0 {
@@ -67,6 +60,11 @@ func checkVolumeMonitor(exchange string, symbols []string) {
 		pkg.GetLogger().Info("Sending volume alert", "exchange", exchange, "message", msg)
 		utils.SendMessage(msg)
 	}
+	if len(recoveredParts) > 0 {
+		msg := "✅ Volume recovered on " + exchange + ":\n" + strings.Join(recoveredParts, "\n")
+		pkg.GetLogger().Info("Sending volume recovery", "exchange", exchange, "message", msg)
+		utils.SendMessage(msg)
+	}
 }
 
 func getNotifyCount() int {
@@ -77,6 +75,15 @@ func getNotifyCount() int {
 	return cfg.VolumeMonitor.NotifyCount
 }
 
+// 获取交易所的 24h 交易量阈值, 未配置时使用默认值
+func getVolumeThreshold(exchange string, provider VolumeProvider) float64 {
+	monitorCfg := findVolumeMonitorConfig(exchange)
+	if monitorCfg != nil && monitorCfg.ThresholdUSD > 0 {
+		return monitorCfg.ThresholdUSD
+	}
+	return provider.DefaultVolumeThreshold()
+}
+
 // 寻找交易所的监控配置
 func findVolumeMonitorConfig(exchange string) *config.VolumeMonitorPlatform {
 	cfg, err := config.LoadConfig()
@@ -91,72 +98,136 @@ func findVolumeMonitorConfig(exchange string) *config.VolumeMonitorPlatform {
 	return nil
 }
 
-type VolumeResponse struct {
-	Symbol string `json:"contract"`
-	Volume string `json:"volume_24h_settle"`
+// --- 持仓量监控相关 ---
+
+type OpenInterest struct {
+	symbol string
+	usd    float64 // 持仓量, 按标记价格折算为美元
 }
 
-// 查询gate交易所的symbol 交易所数据
-func checkGateVolume(symbols []string) ([]PerpTicker, error) {
-	url := "https://api.gateio.ws/api/v4/futures/usdt/tickers"
-	resp, err := pkg.SendGetRequestMarshal[[]VolumeResponse](pkg.GetHTTPClient(), url, nil, nil)
+type openInterestSample struct {
+	ts  int64 // unix 秒
+	usd float64
+}
+
+// 滚动窗口内的持仓量采样, key 为 exchange_symbol
+var openInterestHistory = pkg.NewSimpleCache(nil)
+
+func checkOpenInterestMonitor(exchange string, symbols []string, cfg config.OpenInterestMonitorConfig) {
+	exchange = strings.ToLower(exchange)
+	provider, exists := exchangeCapability[OpenInterestProvider](exchange)
+	if !exists {
+		pkg.GetLogger().Debug("Unsupported exchange for open interest monitor", "exchange", exchange)
+		return
+	}
+
+	start := time.Now()
+	items, err := provider.OpenInterest(symbols)
+	metrics.MonitorRunDuration.WithLabelValues("open_interest", exchange).Observe(time.Since(start).Seconds())
 	if err != nil {
-		pkg.GetLogger().Error("Failed to request gate symbols", "error", err)
-		return nil, err
-	}
-	// 筛选出成交量比 ThresholdUSD 低的symbol
-	symbolSet := make(map[string]struct{})
-	for _, symbol := range symbols {
-		symbolSet[symbol] = struct{}{}
-	}
-	var result []PerpTicker
-	// 找到gate的交易量监控配置
-	var thresholdUSD float64 = 500000 // 默认50w
-	monitorCfg := findVolumeMonitorConfig("gate")
-	if monitorCfg != nil && monitorCfg.ThresholdUSD > 0 {
-		thresholdUSD = monitorCfg.ThresholdUSD
-	}
-	for _, ticker := range resp {
-		if _, exists := symbolSet[ticker.Symbol]; exists {
-			if vol := pkg.StringToFloat(ticker.Volume); vol < thresholdUSD {
-				result = append(result, PerpTicker{
-					symbol:    ticker.Symbol,
-					volume24h: ticker.Volume,
-				})
-			}
+		pkg.GetLogger().Debug("Failed to get open interest", "exchange", exchange, "error", err)
+		return
+	}
+
+	var lowParts, dropParts, recoveredParts []string
+	thresholdUSD := getOpenInterestThreshold(exchange, provider, cfg)
+	policy := defaultAlertPolicy()
+	now := time.Now().Unix()
+
+	for _, item := range items {
+		key := exchange + "_" + item.symbol
+		peak := recordOpenInterest(key, now, item.usd, int64(cfg.Window))
+		detail := fmt.Sprintf("symbol: %s, open interest: $%.0f", item.symbol, item.usd)
+
+		// 1. 持仓量低于阈值
+		if notify, recovered := evaluateAlert("oi:"+key, item.usd < thresholdUSD, policy, cfg.NotifyCount); recovered {
+			recoveredParts = append(recoveredParts, detail)
+		} else if notify {
+			lowParts = append(lowParts, detail)
+		}
+
+		// 2. 窗口内相对最高值的跌幅超过阈值
+		drop := 0.0
+		if peak > 0 {
+			drop = (peak - item.usd) / peak
+		}
+		dropDetail := fmt.Sprintf("%s, peak: $%.0f, drop: %.2f%%", detail, peak, drop*100)
+		if notify, recovered := evaluateAlert("oi_drop:"+key, drop >= cfg.DropThreshold, policy, cfg.NotifyCount); recovered {
+			recoveredParts = append(recoveredParts, dropDetail)
+		} else if notify {
+			dropParts = append(dropParts, dropDetail)
 		}
 	}
-	return result, nil
+
+	pruneOpenInterestHistory(exchange, now, int64(cfg.Window))
+
+	if len(lowParts) > 0 {
+		msg := fmt.Sprintf("Open interest too low on %s (threshold $%.0f):\n%s", exchange, thresholdUSD, strings.Join(lowParts, "\n"))
+		pkg.GetLogger().Info("Sending open interest alert", "exchange", exchange, "message", msg)
+		utils.SendMessage(msg)
+	}
+	if len(dropParts) > 0 {
+		msg := fmt.Sprintf("Open interest dropped more than %.2f%% within %s on %s:\n%s",
+			cfg.DropThreshold*100, formatDuration(cfg.Window), exchange, strings.Join(dropParts, "\n"))
+		pkg.GetLogger().Info("Sending open interest drop alert", "exchange", exchange, "message", msg)
+		utils.SendMessage(msg)
+	}
+	if len(recoveredParts) > 0 {
+		msg := "✅ Open interest recovered on " + exchange + ":\n" + strings.Join(recoveredParts, "\n")
+		pkg.GetLogger().Info("Sending open interest recovery", "exchange", exchange, "message", msg)
+		utils.SendMessage(msg)
+	}
 }
 
-func checkBinanceVolume(symbols []string) ([]PerpTicker, error) {
-	url := "https://fapi.binance.com/fapi/v1/ticker/24hr"
-	resp, err := pkg.SendGetRequestMarshal[[]VolumeResponse](pkg.GetHTTPClient(), url, nil, nil)
-	if err != nil {
-		pkg.GetLogger().Error("Failed to request binance symbols", "error", err)
-		return nil, err
-	}
-	// 筛选出成交量比 ThresholdUSD 低的symbol
-	symbolSet := make(map[string]struct{})
-	for _, symbol := range symbols {
-		symbolSet[symbol] = struct{}{}
-	}
-	var result []PerpTicker
-	// 找到binance的交易量监控配置
-	var thresholdUSD float64 = 5000000 // 默认500w
-	monitorCfg := findVolumeMonitorConfig("binance")
-	if monitorCfg != nil && monitorCfg.ThresholdUSD > 0 {
-		thresholdUSD = monitorCfg.ThresholdUSD
-	}
-	for _, ticker := range resp {
-		if _, exists := symbolSet[ticker.Symbol]; exists {
-			if vol := pkg.StringToFloat(ticker.Volume); vol < thresholdUSD {
-				result = append(result, PerpTicker{
-					symbol:    ticker.Symbol,
-					volume24h: ticker.Volume,
-				})
+// 记录一次采样并丢弃窗口外的采样, 返回窗口内的最高值(含本次)
+func recordOpenInterest(key string, now int64, usd float64, window int64) float64 {
+	var samples []openInterestSample
+	if val, exists := openInterestHistory.Get(key); exists {
+		samples = val.([]openInterestSample)
+	}
+	kept := make([]openInterestSample, 0, len(samples)+1)
+	peak := usd
+	for _, sample := range samples {
+		if now-sample.ts > window {
+			continue
+		}
+		kept = append(kept, sample)
+		peak = max(peak, sample.usd)
+	}
+	kept = append(kept, openInterestSample{ts: now, usd: usd})
+	openInterestHistory.Set(key, kept)
+	return peak
+}
+
+// 丢弃交易所所有 symbol 窗口外的采样, 已下架或不再监控的 symbol 采样全部过期后删除
+func pruneOpenInterestHistory(exchange string, now, window int64) {
+	prefix := exchange + "_"
+	for key, val := range openInterestHistory.Items() {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		samples := val.([]openInterestSample)
+		kept := make([]openInterestSample, 0, len(samples))
+		for _, sample := range samples {
+			if now-sample.ts <= window {
+				kept = append(kept, sample)
 			}
 		}
+		switch {
+		case len(kept) == 0:
+			openInterestHistory.Delete(key)
+		case len(kept) < len(samples):
+			openInterestHistory.Set(key, kept)
+		}
+	}
+}
+
+// 获取交易所的持仓量阈值, 未配置时使用默认值
+func getOpenInterestThreshold(exchange string, provider OpenInterestProvider, cfg config.OpenInterestMonitorConfig) float64 {
+	for _, platform := range cfg.Platform {
+		if strings.EqualFold(platform.Platform, exchange) && platform.ThresholdUSD > 0 {
+			return platform.ThresholdUSD
+		}
 	}
-	return result, nil
+	return provider.DefaultOpenInterestThreshold()
 }
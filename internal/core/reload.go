@@ -0,0 +1,45 @@
+package core
+
+import (
+	"reflect"
+
+	"github.com/fuxingjun/balance-bot/internal/config"
+	"github.com/fuxingjun/balance-bot/internal/utils"
+	"github.com/fuxingjun/balance-bot/pkg"
+)
+
+// ApplyConfigChange 增量应用新配置: 增删监控地址、重设定时任务、重建通知渠道
+func ApplyConfigChange(old, new *config.AppConfig) {
+	if old == nil || new == nil {
+		return
+	}
+	// 1. 监控地址: 新增与修改的立即检测一次, 删除的清理状态
+	added, removed, changed := config.TokenChanges(old, new)
+	for i := range removed {
+		forgetBalanceItem(&removed[i])
+	}
+	previous := make(map[string]config.TokenConfig, len(old.Tokens))
+	for _, token := range old.Tokens {
+		previous[token.Key()] = token
+	}
+	for i := range changed {
+		prev := previous[changed[i].Key()]
+		forgetBalanceMetric(&prev, &changed[i])
+	}
+	if checks := append(added, changed...); len(checks) > 0 {
+		runBalanceCheck(checks)
+	}
+	// 2. 余额检测间隔
+	if old.Interval != new.Interval {
+		rescheduleBalanceCheck(new)
+	}
+	// 3. 健康检测间隔与服务心跳策略
+	if !reflect.DeepEqual(old.HealthCheck, new.HealthCheck) {
+		rescheduleHealthTimers(new)
+	}
+	// 4. 通知渠道
+	if !reflect.DeepEqual(old.Webhook, new.Webhook) {
+		utils.ReloadNotifiers(new.Webhook)
+	}
+	pkg.GetLogger().Info("Config change applied", "added", len(added), "removed", len(removed), "changed", len(changed))
+}
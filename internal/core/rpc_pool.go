@@ -0,0 +1,390 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fuxingjun/balance-bot/internal/metrics"
+	"github.com/fuxingjun/balance-bot/pkg"
+	"github.com/gofiber/fiber/v2"
+)
+
+// --- RPC 节点健康评分与故障转移 ---
+
+const (
+	rpcMaxConsecutiveFailures = 3                // 连续失败多少次后剔除
+	rpcEjectBaseDuration      = 30 * time.Second // 首次剔除时长, 之后按次数翻倍
+	rpcEjectMaxDuration       = 10 * time.Minute // 最长剔除时长
+	rpcMaxBlockLag            = 20               // 落后多数节点多少个区块后剔除
+	rpcProbeInterval          = 30 * time.Second // 区块高度探测间隔
+	rpcLatencyAlpha           = 0.3              // 延迟 EWMA 平滑系数
+	rpcEjectResetSuccesses    = 10               // 恢复后连续成功多少次清零剔除次数, 剔除时长重新从首次开始
+)
+
+// rpcEndpoint 单个 RPC 节点的统计信息
+type rpcEndpoint struct {
+	URL                 string
+	Success             int64
+	Failure             int64
+	ConsecutiveFailures int
+	ConsecutiveSuccess  int     // 在轮换中连续成功的次数, 用于清零 EjectCount
+	LatencyMs           float64 // 延迟指数移动平均
+	BlockHeight         uint64
+	BlockUpdatedAt      time.Time
+	EjectCount          int
+	EjectedUntil        time.Time
+	EjectReason         string
+	LastError           string
+}
+
+func (e *rpcEndpoint) inRotation(now time.Time) bool {
+	return now.After(e.EjectedUntil)
+}
+
+func (e *rpcEndpoint) eject(reason string, now time.Time) {
+	duration := rpcEjectBaseDuration << min(e.EjectCount, 10)
+	if duration > rpcEjectMaxDuration {
+		duration = rpcEjectMaxDuration
+	}
+	e.EjectCount++
+	e.ConsecutiveSuccess = 0
+	e.EjectedUntil = now.Add(duration)
+	e.EjectReason = reason
+	pkg.GetLogger().Warn("RPC endpoint ejected", "url", e.URL, "reason", reason, "duration", duration)
+}
+
+// rpcPool 一条链的 RPC 节点池
+type rpcPool struct {
+	chainId   string
+	endpoints []*rpcEndpoint
+	point     int
+	mu        sync.Mutex
+}
+
+var (
+	rpcPools      = make(map[string]*rpcPool)
+	rpcPoolsMutex sync.Mutex
+)
+
+// 获取链的节点池, 配置中的 RPC 列表变化时同步节点并保留已有统计
+func getRPCPool(chainId string) (*rpcPool, error) {
+	chain, err := getChainConfig(chainId)
+	if err != nil {
+		return nil, err
+	}
+	if len(chain.RPC) == 0 {
+		return nil, fmt.Errorf("no rpc configured for chain %s", chainId)
+	}
+	rpcPoolsMutex.Lock()
+	pool, exists := rpcPools[chainId]
+	if !exists {
+		pool = &rpcPool{chainId: chainId}
+		rpcPools[chainId] = pool
+	}
+	rpcPoolsMutex.Unlock()
+
+	pool.sync(chain.RPC)
+	return pool, nil
+}
+
+func (p *rpcPool) sync(urls []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(urls) == len(p.endpoints) {
+		same := true
+		for i, ep := range p.endpoints {
+			if ep.URL != urls[i] {
+				same = false
+				break
+			}
+		}
+		if same {
+			return
+		}
+	}
+	existing := make(map[string]*rpcEndpoint, len(p.endpoints))
+	for _, ep := range p.endpoints {
+		existing[ep.URL] = ep
+	}
+	endpoints := make([]*rpcEndpoint, 0, len(urls))
+	for _, url := range urls {
+		if ep, ok := existing[url]; ok {
+			endpoints = append(endpoints, ep)
+		} else {
+			endpoints = append(endpoints, &rpcEndpoint{URL: url})
+		}
+	}
+	p.endpoints = endpoints
+	p.point = 0
+}
+
+// 按轮询顺序返回在轮换中的节点, 全部被剔除时退化为返回所有节点
+func (p *rpcPool) candidates() []*rpcEndpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n := len(p.endpoints)
+	if n == 0 {
+		return nil
+	}
+	if p.point >= n {
+		p.point = 0
+	}
+	start := p.point
+	p.point++
+
+	now := time.Now()
+	var healthy, ejected []*rpcEndpoint
+	for i := 0; i < n; i++ {
+		ep := p.endpoints[(start+i)%n]
+		if ep.inRotation(now) {
+			healthy = append(healthy, ep)
+		} else {
+			ejected = append(ejected, ep)
+		}
+	}
+	if len(healthy) == 0 {
+		// 优先尝试最早恢复的节点
+		sort.Slice(ejected, func(i, j int) bool {
+			return ejected[i].EjectedUntil.Before(ejected[j].EjectedUntil)
+		})
+		return ejected
+	}
+	return healthy
+}
+
+// 记录一次请求结果
+func (p *rpcPool) report(ep *rpcEndpoint, latency time.Duration, err error) {
+	endpoint := metrics.EndpointLabel(ep.URL)
+	metrics.RPCRequests.WithLabelValues(p.chainId, endpoint, metrics.Result(err)).Inc()
+	metrics.RPCLatency.WithLabelValues(p.chainId, endpoint).Observe(latency.Seconds())
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ms := float64(latency.Milliseconds())
+	if ep.LatencyMs == 0 {
+		ep.LatencyMs = ms
+	} else {
+		ep.LatencyMs = rpcLatencyAlpha*ms + (1-rpcLatencyAlpha)*ep.LatencyMs
+	}
+	if err == nil {
+		ep.Success++
+		ep.ConsecutiveFailures = 0
+		ep.LastError = ""
+		// 剔除期间的探测成功不计入, 重新进入轮换后稳定一段时间才清零剔除次数
+		if ep.EjectCount > 0 && ep.inRotation(time.Now()) {
+			ep.ConsecutiveSuccess++
+			if ep.ConsecutiveSuccess >= rpcEjectResetSuccesses {
+				ep.EjectCount = 0
+				ep.ConsecutiveSuccess = 0
+			}
+		}
+		return
+	}
+	ep.Failure++
+	ep.ConsecutiveFailures++
+	ep.ConsecutiveSuccess = 0
+	ep.LastError = err.Error()
+	if ep.ConsecutiveFailures >= rpcMaxConsecutiveFailures && ep.inRotation(time.Now()) {
+		ep.eject(fmt.Sprintf("%d consecutive failures: %v", ep.ConsecutiveFailures, err), time.Now())
+	}
+}
+
+// Do 依次在健康节点上执行请求, 失败时切换到下一个节点
+func (p *rpcPool) Do(call func(url string) error) error {
+	candidates := p.candidates()
+	if len(candidates) == 0 {
+		return fmt.Errorf("no rpc available for chain %s", p.chainId)
+	}
+	var errs []error
+	for _, ep := range candidates {
+		start := time.Now()
+		err := call(ep.URL)
+		if err != nil && !isEndpointError(err) {
+			// 节点正常响应了业务错误, 不计入失败, 也不重试
+			p.report(ep, time.Since(start), nil)
+			return err
+		}
+		p.report(ep, time.Since(start), err)
+		if err == nil {
+			return nil
+		}
+		pkg.GetLogger().Debug("RPC request failed, trying next endpoint", "chain", p.chainId, "url", ep.URL, "error", err)
+		errs = append(errs, fmt.Errorf("%s: %w", ep.URL, err))
+	}
+	return fmt.Errorf("all rpc endpoints failed for chain %s: %w", p.chainId, errors.Join(errs...))
+}
+
+// rpcError JSON-RPC 返回的 error 对象
+type rpcError struct {
+	Code    int
+	Message string
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("RPC error: %s", e.Message)
+}
+
+// 判断错误是否由节点本身导致, 只有这类错误才计入失败并切换节点
+func isEndpointError(err error) bool {
+	var rpcErr *rpcError
+	if errors.As(err, &rpcErr) {
+		// -32005 为节点限流
+		return rpcErr.Code == -32005
+	}
+	return true
+}
+
+// 探测节点区块高度, 剔除落后于多数节点的节点
+func (p *rpcPool) probe() {
+	p.mu.Lock()
+	endpoints := slices.Clone(p.endpoints)
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, ep := range endpoints {
+		wg.Add(1)
+		go func(ep *rpcEndpoint) {
+			defer wg.Done()
+			start := time.Now()
+			height, err := getBlockNumber(ep.URL)
+			p.report(ep, time.Since(start), err)
+			if err != nil {
+				return
+			}
+			p.mu.Lock()
+			ep.BlockHeight = height
+			ep.BlockUpdatedAt = time.Now()
+			p.mu.Unlock()
+		}(ep)
+	}
+	wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	var heights []uint64
+	for _, ep := range endpoints {
+		if ep.BlockHeight > 0 && now.Sub(ep.BlockUpdatedAt) < 2*rpcProbeInterval {
+			heights = append(heights, ep.BlockHeight)
+		}
+	}
+	if len(heights) < 2 {
+		return
+	}
+	slices.Sort(heights)
+	median := heights[len(heights)/2]
+	for _, ep := range endpoints {
+		if ep.BlockHeight == 0 || !ep.inRotation(now) {
+			continue
+		}
+		if ep.BlockHeight+rpcMaxBlockLag < median {
+			ep.eject(fmt.Sprintf("block height %d behind majority %d", ep.BlockHeight, median), now)
+		}
+	}
+}
+
+// 直接向指定节点查询最新区块高度
+func getBlockNumber(url string) (uint64, error) {
+	params := map[string]any{
+		"jsonrpc": "2.0",
+		"method":  "eth_blockNumber",
+		"params":  []any{},
+		"id":      pkg.GetSimpleId(),
+	}
+	result, err := postRPC(url, params)
+	if err != nil {
+		return 0, err
+	}
+	height := pkg.HexToBigInt(result)
+	if !height.IsUint64() || height.Uint64() == 0 {
+		return 0, fmt.Errorf("invalid block number: %s", result)
+	}
+	return height.Uint64(), nil
+}
+
+// 后台定期探测所有已使用链的节点
+func StartRPCHealthProbe() {
+	pkg.GetLogger().Info("Starting rpc health probe...")
+	for {
+		rpcPoolsMutex.Lock()
+		pools := make([]*rpcPool, 0, len(rpcPools))
+		for _, pool := range rpcPools {
+			pools = append(pools, pool)
+		}
+		rpcPoolsMutex.Unlock()
+
+		for _, pool := range pools {
+			pool.probe()
+		}
+		time.Sleep(rpcProbeInterval)
+	}
+}
+
+type RPCEndpointStatus struct {
+	URL                 string  `json:"url"`
+	InRotation          bool    `json:"inRotation"`
+	Success             int64   `json:"success"`
+	Failure             int64   `json:"failure"`
+	SuccessRate         float64 `json:"successRate"`
+	ConsecutiveFailures int     `json:"consecutiveFailures"`
+	LatencyMs           float64 `json:"latencyMs"`
+	BlockHeight         uint64  `json:"blockHeight"`
+	EjectedUntil        string  `json:"ejectedUntil,omitempty"`
+	EjectReason         string  `json:"ejectReason,omitempty"`
+	LastError           string  `json:"lastError,omitempty"`
+}
+
+// 获取所有节点池状态, key 为 chainId
+func GetRPCPoolStatus() map[string][]RPCEndpointStatus {
+	rpcPoolsMutex.Lock()
+	pools := make(map[string]*rpcPool, len(rpcPools))
+	for id, pool := range rpcPools {
+		pools[id] = pool
+	}
+	rpcPoolsMutex.Unlock()
+
+	now := time.Now()
+	result := make(map[string][]RPCEndpointStatus, len(pools))
+	for id, pool := range pools {
+		pool.mu.Lock()
+		list := make([]RPCEndpointStatus, 0, len(pool.endpoints))
+		for _, ep := range pool.endpoints {
+			status := RPCEndpointStatus{
+				URL:                 ep.URL,
+				InRotation:          ep.inRotation(now),
+				Success:             ep.Success,
+				Failure:             ep.Failure,
+				ConsecutiveFailures: ep.ConsecutiveFailures,
+				LatencyMs:           ep.LatencyMs,
+				BlockHeight:         ep.BlockHeight,
+				LastError:           ep.LastError,
+			}
+			if total := ep.Success + ep.Failure; total > 0 {
+				status.SuccessRate = float64(ep.Success) / float64(total)
+			}
+			if !status.InRotation {
+				status.EjectedUntil = ep.EjectedUntil.Format(time.RFC3339)
+				status.EjectReason = ep.EjectReason
+			}
+			list = append(list, status)
+		}
+		pool.mu.Unlock()
+		result[id] = list
+	}
+	return result
+}
+
+// RPCStatus 查询 RPC 节点池状态
+func RPCStatus(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"status": "ok",
+		"data":   GetRPCPoolStatus(),
+	})
+}
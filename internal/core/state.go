@@ -0,0 +1,156 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/fuxingjun/balance-bot/internal/config"
+	"github.com/fuxingjun/balance-bot/pkg"
+)
+
+// --- 监控状态持久化, 重启后恢复 ---
+
+const (
+	stateBucketSymbols  = "symbols"  // symbolsCache
+	stateBucketIndex    = "index"    // indexCache
+	stateBucketNotify   = "notify"   // notifyCache
+	stateBucketAlerts   = "alerts"   // alertStore
+	stateBucketHealth   = "health"   // healthStore
+	stateBucketFunding  = "funding"  // fundingIntervalCache
+	stateBucketPairs    = "pairs"    // pairsCache
+	stateBucketSpread   = "spread"   // spreadSignCache
+	stateBucketContract = "contract" // contractCache
+)
+
+// 状态存储, 未启用时为 nil
+var stateStore pkg.Store
+
+// RestoreState 打开状态存储, 恢复各缓存与健康检测状态, 之后的变更会同步写入存储
+func RestoreState() error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	if cfg == nil || cfg.State.Disabled {
+		return nil
+	}
+	store, err := pkg.NewFileStore(cfg.State.Dir)
+	if err != nil {
+		return fmt.Errorf("open state store failed: %w", err)
+	}
+	if err := symbolsCache.Persist(store, stateBucketSymbols, decodeState[[]string]); err != nil {
+		return err
+	}
+	if err := indexCache.Persist(store, stateBucketIndex, decodeState[[]IndexConstituent]); err != nil {
+		return err
+	}
+	if err := notifyCache.Persist(store, stateBucketNotify, decodeState[int]); err != nil {
+		return err
+	}
+	if err := fundingIntervalCache.Persist(store, stateBucketFunding, decodeState[int]); err != nil {
+		return err
+	}
+	if err := pairsCache.Persist(store, stateBucketPairs, decodeState[PairInfo]); err != nil {
+		return err
+	}
+	if err := spreadSignCache.Persist(store, stateBucketSpread, decodeState[int]); err != nil {
+		return err
+	}
+	if err := contractCache.Persist(store, stateBucketContract, decodeState[contractFields]); err != nil {
+		return err
+	}
+	if err := restoreAlerts(store); err != nil {
+		return err
+	}
+	if err := restoreHealth(store, cfg); err != nil {
+		return err
+	}
+	stateStore = store
+	pkg.GetLogger().Info("State restored", "dir", cfg.State.Dir, "symbols", symbolsCache.Len(), "health", len(healthStore))
+	return nil
+}
+
+func decodeState[T any](key string, raw json.RawMessage) (any, error) {
+	var value T
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func restoreAlerts(store pkg.Store) error {
+	records, err := store.Load(stateBucketAlerts)
+	if err != nil {
+		return err
+	}
+	alertMutex.Lock()
+	defer alertMutex.Unlock()
+	for key, raw := range records {
+		var state AlertState
+		if err := json.Unmarshal(raw, &state); err != nil {
+			continue
+		}
+		alertStore[key] = &state
+	}
+	return nil
+}
+
+// 恢复已注册的服务并重新设置超时任务, 给服务至少一个检测间隔的时间重新上报心跳
+func restoreHealth(store pkg.Store, cfg *config.AppConfig) error {
+	records, err := store.Load(stateBucketHealth)
+	if err != nil {
+		return err
+	}
+	storeMutex.Lock()
+	defer storeMutex.Unlock()
+	now := int(time.Now().Unix())
+	for name, raw := range records {
+		var status HealthStatus
+		if err := json.Unmarshal(raw, &status); err != nil {
+			continue
+		}
+		status.IsAlerting = false
+		if status.RegisteredAt == 0 {
+			status.RegisteredAt = status.LastHeartbeat
+		}
+		restored := &status
+		policy := restored.policy(cfg, name)
+		delay := max(status.LastHeartbeat+policy.Timeout()-now, policy.Timeout())
+		restored.NotifyTask = time.AfterFunc(time.Duration(delay)*time.Second, func() {
+			handleHealthCheckTimeout(name, restored)
+		})
+		healthStore[name] = restored
+	}
+	return nil
+}
+
+// CloseState 关闭状态存储, 将 WAL 合并到快照, 在进程退出前调用
+func CloseState() {
+	if stateStore == nil {
+		return
+	}
+	if err := stateStore.Close(); err != nil {
+		pkg.GetLogger().Warn("Failed to close state store", "error", err)
+	}
+}
+
+// 写入状态, 未启用持久化时忽略
+func saveState(bucket, key string, value any) {
+	if stateStore == nil {
+		return
+	}
+	if err := stateStore.Put(bucket, key, value); err != nil {
+		pkg.GetLogger().Warn("Failed to save state", "bucket", bucket, "key", key, "error", err)
+	}
+}
+
+// 删除状态, 未启用持久化时忽略
+func deleteState(bucket, key string) {
+	if stateStore == nil {
+		return
+	}
+	if err := stateStore.Delete(bucket, key); err != nil {
+		pkg.GetLogger().Warn("Failed to delete state", "bucket", bucket, "key", key, "error", err)
+	}
+}
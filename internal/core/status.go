@@ -0,0 +1,262 @@
+package core
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fuxingjun/balance-bot/internal/config"
+	"github.com/fuxingjun/balance-bot/internal/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// --- 只读状态接口, 支持分页与过滤 ---
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// 按 page / size 查询参数分页
+func paginate[T any](c *fiber.Ctx, items []T) fiber.Map {
+	page := max(c.QueryInt("page", 1), 1)
+	size := c.QueryInt("size", defaultPageSize)
+	if size <= 0 {
+		size = defaultPageSize
+	}
+	size = min(size, maxPageSize)
+	// 先判断页码是否越界, 避免 (page-1)*size 溢出
+	start := len(items)
+	if page-1 <= len(items)/size {
+		start = min((page-1)*size, len(items))
+	}
+	end := min(start+size, len(items))
+	return fiber.Map{
+		"status": "ok",
+		"data": fiber.Map{
+			"total": len(items),
+			"page":  page,
+			"size":  size,
+			"items": items[start:end],
+		},
+	}
+}
+
+// 过滤条件为空时视为匹配
+func matchFilter(filter, value string) bool {
+	return filter == "" || utils.ContainsIgnoreCase(value, filter)
+}
+
+// BalanceStatus 查询每个地址最近一次余额读数, 支持 chain / name 过滤
+func BalanceStatus(c *fiber.Ctx) error {
+	cfg, err := config.LoadConfig()
+	if err != nil || cfg == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to load config",
+		})
+	}
+	chain := c.Query("chain")
+	name := c.Query("name")
+	result := make([]BalanceReading, 0)
+	for _, reading := range getBalanceReadings(cfg.Tokens) {
+		if chain != "" && reading.ChainId != chain {
+			continue
+		}
+		if !matchFilter(name, reading.Name) && !matchFilter(name, reading.Address) {
+			continue
+		}
+		result = append(result, reading)
+	}
+	return c.JSON(paginate(c, result))
+}
+
+type HealthEntry struct {
+	Name          string                     `json:"name"`
+	LastHeartbeat string                     `json:"lastHeartbeat"`
+	NextCheck     string                     `json:"nextCheck"`
+	WarnCount     int                        `json:"warnCount"`
+	IsAlerting    bool                       `json:"isAlerting"`
+	Policy        config.HealthServiceConfig `json:"policy"`                 // 生效的心跳策略
+	RuleFailures  []string                   `json:"ruleFailures,omitempty"` // 最近一次心跳未满足的断言
+	PausedUntil   string                     `json:"pausedUntil,omitempty"`  // 暂停告警截止时间
+	SLA           map[string]float64         `json:"sla"`                    // 最近 1 天 / 7 天的可用率(百分比)
+}
+
+// 转换为接口返回的结构, 调用方需持有 storeMutex
+func toHealthEntry(name string, status *HealthStatus, cfg *config.AppConfig, now int) HealthEntry {
+	policy := status.policy(cfg, name)
+	entry := HealthEntry{
+		Name:          name,
+		LastHeartbeat: formatTime(status.LastHeartbeat),
+		NextCheck:     formatTime(status.LastHeartbeat + policy.Timeout()),
+		WarnCount:     status.WarnCount,
+		IsAlerting:    status.IsAlerting || isAlertFiring(healthAlertKey(name)) || isAlertFiring(healthRuleAlertKey(name)),
+		Policy:        policy,
+		RuleFailures:  status.RuleFailures,
+		SLA:           status.sla(name, cfg, now),
+	}
+	if status.PausedUntil > now {
+		entry.PausedUntil = formatTime(status.PausedUntil)
+	}
+	return entry
+}
+
+// HealthStatusList 查询所有已注册的健康检测服务, 支持 name 过滤
+func HealthStatusList(c *fiber.Ctx) error {
+	cfg, err := config.LoadConfig()
+	if err != nil || cfg == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to load config",
+		})
+	}
+	name := c.Query("name")
+	now := int(time.Now().Unix())
+	storeMutex.RLock()
+	result := make([]HealthEntry, 0, len(healthStore))
+	for serviceName, status := range healthStore {
+		if !matchFilter(name, serviceName) {
+			continue
+		}
+		result = append(result, toHealthEntry(serviceName, status, cfg, now))
+	}
+	storeMutex.RUnlock()
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return c.JSON(paginate(c, result))
+}
+
+type OutageEntry struct {
+	Start       string `json:"start"`
+	End         string `json:"end"`
+	Duration    int    `json:"duration"` // 秒
+	MissedBeats int    `json:"missedBeats"`
+	FirstMissed string `json:"firstMissed,omitempty"`
+	LastMissed  string `json:"lastMissed,omitempty"`
+	Planned     bool   `json:"planned,omitempty"`
+}
+
+// HealthServiceDetail 查询单个服务的状态、可用率与最近 7 天的中断记录, 新的在前
+func HealthServiceDetail(c *fiber.Ctx) error {
+	cfg, err := config.LoadConfig()
+	if err != nil || cfg == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to load config",
+		})
+	}
+	name := c.Params("name")
+	now := int(time.Now().Unix())
+	storeMutex.RLock()
+	status, exists := healthStore[name]
+	if !exists {
+		storeMutex.RUnlock()
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "service not found: " + name,
+		})
+	}
+	entry := toHealthEntry(name, status, cfg, now)
+	outages := make([]OutageEntry, 0, len(status.Outages))
+	for i := len(status.Outages) - 1; i >= 0; i-- {
+		o := status.Outages[i]
+		item := OutageEntry{
+			Start:       formatTime(o.Start),
+			End:         formatTime(o.End),
+			Duration:    o.End - o.Start,
+			MissedBeats: o.MissedBeats,
+			Planned:     o.Planned,
+		}
+		if o.MissedBeats > 0 {
+			item.FirstMissed = formatTime(o.FirstMissed)
+			item.LastMissed = formatTime(o.LastMissed)
+		}
+		outages = append(outages, item)
+	}
+	storeMutex.RUnlock()
+	return c.JSON(fiber.Map{
+		"status": "ok",
+		"data": fiber.Map{
+			"service": entry,
+			"outages": outages,
+		},
+	})
+}
+
+type ExchangeSymbols struct {
+	Exchange string   `json:"exchange"`
+	Symbols  []string `json:"symbols"`
+}
+
+// SymbolsStatus 查询各交易所正在监控的 symbol, 支持 exchange / name 过滤
+func SymbolsStatus(c *fiber.Ctx) error {
+	exchange := strings.ToLower(c.Query("exchange"))
+	name := c.Query("name")
+	result := make([]ExchangeSymbols, 0)
+	for exch, symbols := range symbolsCache.GetAllKeys() {
+		if exchange != "" && exch != exchange {
+			continue
+		}
+		var matched []string
+		for _, symbol := range symbols {
+			if matchFilter(name, symbol) {
+				matched = append(matched, symbol)
+			}
+		}
+		if len(matched) > 0 {
+			result = append(result, ExchangeSymbols{Exchange: exch, Symbols: matched})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Exchange < result[j].Exchange
+	})
+	return c.JSON(paginate(c, result))
+}
+
+type IndexConstituent struct {
+	Exchange string `json:"exchange"`
+	Symbol   string `json:"symbol"`
+	Price    string `json:"price,omitempty"`
+	Weight   string `json:"weight"`
+}
+
+type IndexEntry struct {
+	Exchange     string             `json:"exchange"`
+	Symbol       string             `json:"symbol"`
+	Constituents []IndexConstituent `json:"constituents"`
+}
+
+// 将指数成份缓存转换为接口返回格式
+func toIndexEntry(key string, value any) (IndexEntry, bool) {
+	constituents, ok := value.([]IndexConstituent)
+	if !ok {
+		return IndexEntry{}, false
+	}
+	exchange, symbol, _ := strings.Cut(key, "_index_")
+	return IndexEntry{Exchange: exchange, Symbol: symbol, Constituents: constituents}, true
+}
+
+// IndexStatus 查询最新的指数成份, 支持 exchange / name 过滤
+func IndexStatus(c *fiber.Ctx) error {
+	exchange := strings.ToLower(c.Query("exchange"))
+	name := c.Query("name")
+	result := make([]IndexEntry, 0)
+	for key, value := range indexCache.Items() {
+		entry, ok := toIndexEntry(key, value)
+		if !ok {
+			continue
+		}
+		if exchange != "" && entry.Exchange != exchange {
+			continue
+		}
+		if !matchFilter(name, entry.Symbol) {
+			continue
+		}
+		result = append(result, entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Exchange != result[j].Exchange {
+			return result[i].Exchange < result[j].Exchange
+		}
+		return result[i].Symbol < result[j].Symbol
+	})
+	return c.JSON(paginate(c, result))
+}
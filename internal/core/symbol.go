@@ -0,0 +1,50 @@
+package core
+
+import (
+	"strings"
+)
+
+// --- 交易对名称归一化 ---
+
+// 常见计价币, 用于拆分没有分隔符的 symbol, 如 BTCUSDT
+var quoteAssets = []string{"USDT", "USDC", "USD"}
+
+// 将提交的 symbol 转为交易所永续合约的原生格式, 如 gate 的 BTC-USDT-SWAP 转为 BTC_USDT
+// 只处理永续合约腿(type 为 perp 或为空), 无法识别的 symbol 原样返回
+func normalizeSymbol(exchange, legType, symbol string) string {
+	if legType != "" && !strings.EqualFold(legType, "perp") {
+		return symbol
+	}
+	adapter, exists := exchanges[strings.ToLower(exchange)]
+	if !exists {
+		return symbol
+	}
+	base, quote, ok := splitSymbol(symbol)
+	if !ok {
+		return symbol
+	}
+	return adapter.PerpSymbol(base, quote)
+}
+
+// 拆分出 base 与 quote, 支持 BTC_USDT、BTC-USDT、BTC/USDT、BTCUSDT、BTC-USDT-SWAP、BTC/USDT:USDT 与 BTCUSDT_UMCBL
+func splitSymbol(symbol string) (string, string, bool) {
+	s := strings.ToUpper(strings.TrimSpace(symbol))
+	for _, suffix := range []string{"-SWAP", "_UMCBL", "_PERP"} {
+		s = strings.TrimSuffix(s, suffix)
+	}
+	if i := strings.Index(s, ":"); i >= 0 {
+		s = s[:i]
+	}
+	if base, quote, found := strings.Cut(strings.NewReplacer("-", "_", "/", "_").Replace(s), "_"); found {
+		if base == "" || quote == "" || strings.Contains(quote, "_") {
+			return "", "", false
+		}
+		return base, quote, true
+	}
+	for _, quote := range quoteAssets {
+		if base, found := strings.CutSuffix(s, quote); found && base != "" {
+			return base, quote, true
+		}
+	}
+	return "", "", false
+}
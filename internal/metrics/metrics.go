@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"net/url"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// --- Prometheus 指标定义, 通过 /metrics 暴露 ---
+
+const namespace = "balance_bot"
+
+var (
+	// 监控地址的最新余额
+	Balance = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "balance",
+		Help:      "Latest balance of monitored address.",
+	}, []string{"chain", "address", "name", "contract"})
+
+	// RPC 请求次数, result 为 success / failure
+	RPCRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rpc_requests_total",
+		Help:      "Total RPC requests by chain and endpoint.",
+	}, []string{"chain", "endpoint", "result"})
+
+	// RPC 请求耗时
+	RPCLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_request_duration_seconds",
+		Help:      "RPC request latency by chain and endpoint.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"chain", "endpoint"})
+
+	// 通知发送次数, result 为 success / failure
+	NotifySent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notify_total",
+		Help:      "Notifications sent by channel and result.",
+	}, []string{"channel", "result"})
+
+	// 监控任务单轮耗时, monitor 为 volume / index 等
+	MonitorRunDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "monitor_run_duration_seconds",
+		Help:      "Duration of a single monitor run by monitor and exchange.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"monitor", "exchange"})
+)
+
+// Result 将错误转换为 result 标签值
+func Result(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
+
+// EndpointLabel 只保留 RPC 地址的 host, 避免路径中的 API key 出现在指标中
+func EndpointLabel(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "unknown"
+	}
+	return u.Host
+}
@@ -6,9 +6,12 @@ import (
 )
 
 type Args struct {
-	Host  string
-	Port  int
-	Debug bool
+	Host    string
+	Port    int
+	Debug   bool
+	Config  string   // 配置文件路径, 按扩展名识别 JSON / YAML / TOML
+	Command string   // 子命令, 为空表示启动服务
+	Params  []string // 子命令参数
 }
 
 var once sync.Once
@@ -20,7 +23,12 @@ func GetArgs() *Args {
 		flag.StringVar(&args.Host, "host", "127.0.0.1", "服务地址")
 		flag.IntVar(&args.Port, "port", 12808, "服务端口")
 		flag.BoolVar(&args.Debug, "debug", false, "是否开启调试模式")
+		flag.StringVar(&args.Config, "config", "config.json", "配置文件路径, 支持 .json / .yaml / .yml / .toml")
 		flag.Parse()
+		if flag.NArg() > 0 {
+			args.Command = flag.Arg(0)
+			args.Params = flag.Args()[1:]
+		}
 	})
 	return args
 }
@@ -2,8 +2,8 @@ package utils
 
 import (
 	"fmt"
+	"sort"
 
-	"github.com/fuxingjun/balance-bot/internal/config"
 	"github.com/fuxingjun/balance-bot/pkg"
 )
 
@@ -40,31 +40,39 @@ func SendTelegramMessage(msg, chatId, token string) error {
 	return err
 }
 
+// SendMessage 并发发送到所有已配置的通知渠道, 任一渠道失败时返回汇总错误, 不影响其它渠道的发送
 func SendMessage(msg string) error {
-	appConfig, err := config.LoadConfig()
+	result, err := Broadcast(msg)
 	if err != nil {
 		return err
 	}
-	if appConfig == nil {
-		return fmt.Errorf("config is nil")
+	logSendResult(result)
+	return result.Err()
+}
+
+// SendMessageTo 发送到指定名称的通知渠道, channels 为空或均未配置时发送到所有渠道
+func SendMessageTo(channels []string, msg string) error {
+	if len(channels) == 0 {
+		return SendMessage(msg)
 	}
-	if appConfig.Webhook.TelegramToken != "" && appConfig.Webhook.TelegramChatId != "" {
-		err := SendTelegramMessage(msg, appConfig.Webhook.TelegramChatId, appConfig.Webhook.TelegramToken)
-		if err != nil {
-			return err
-		}
+	result, err := Multicast(channels, msg)
+	if err != nil {
+		return err
 	}
-	if appConfig.Webhook.Wecom != "" {
-		err := SendWecomMessage(msg, appConfig.Webhook.Wecom)
-		if err != nil {
-			return err
-		}
+	logSendResult(result)
+	return result.Err()
+}
+
+// 部分渠道失败时汇总记录, 单个渠道的错误已在 Deliver 中记录
+func logSendResult(result *SendResult) {
+	failed := result.Failed()
+	if len(failed) == 0 {
+		return
 	}
-	if appConfig.Webhook.Lark != "" {
-		err := SendLarkMessage(msg, appConfig.Webhook.Lark)
-		if err != nil {
-			return err
-		}
+	failedChannels := make([]string, 0, len(failed))
+	for channel := range failed {
+		failedChannels = append(failedChannels, channel)
 	}
-	return nil
+	sort.Strings(failedChannels)
+	pkg.GetLogger().Warn("Notification not delivered to all channels", "succeeded", result.Succeeded(), "failed", failedChannels)
 }
@@ -0,0 +1,227 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fuxingjun/balance-bot/internal/config"
+	"github.com/fuxingjun/balance-bot/internal/metrics"
+	"github.com/fuxingjun/balance-bot/pkg"
+)
+
+// Notifier 通知渠道
+type Notifier interface {
+	Name() string
+	Send(msg string) error
+}
+
+// notifierFactory 根据 webhook 配置创建通知渠道, 未配置时返回 nil
+type notifierFactory func(cfg config.WebhookConfig) Notifier
+
+// 通知渠道注册表, 新增渠道只需实现 Notifier 并在此注册
+var notifierFactories = map[string]notifierFactory{
+	"telegram": newTelegramNotifier,
+	"wecom":    newWecomNotifier,
+	"lark":     newLarkNotifier,
+}
+
+type telegramNotifier struct {
+	token  string
+	chatId string
+}
+
+func newTelegramNotifier(cfg config.WebhookConfig) Notifier {
+	if cfg.TelegramToken == "" || cfg.TelegramChatId == "" {
+		return nil
+	}
+	return &telegramNotifier{token: cfg.TelegramToken, chatId: cfg.TelegramChatId}
+}
+
+func (n *telegramNotifier) Name() string { return "telegram" }
+
+func (n *telegramNotifier) Send(msg string) error {
+	return SendTelegramMessage(msg, n.chatId, n.token)
+}
+
+type wecomNotifier struct {
+	hook string
+}
+
+func newWecomNotifier(cfg config.WebhookConfig) Notifier {
+	if cfg.Wecom == "" {
+		return nil
+	}
+	return &wecomNotifier{hook: cfg.Wecom}
+}
+
+func (n *wecomNotifier) Name() string { return "wecom" }
+
+func (n *wecomNotifier) Send(msg string) error {
+	return SendWecomMessage(msg, n.hook)
+}
+
+type larkNotifier struct {
+	hook string
+}
+
+func newLarkNotifier(cfg config.WebhookConfig) Notifier {
+	if cfg.Lark == "" {
+		return nil
+	}
+	return &larkNotifier{hook: cfg.Lark}
+}
+
+func (n *larkNotifier) Name() string { return "lark" }
+
+func (n *larkNotifier) Send(msg string) error {
+	return SendLarkMessage(msg, n.hook)
+}
+
+// BuildNotifiers 根据配置创建所有已配置的通知渠道, 按名称排序
+func BuildNotifiers(cfg config.WebhookConfig) []Notifier {
+	var notifiers []Notifier
+	for _, factory := range notifierFactories {
+		if notifier := factory(cfg); notifier != nil {
+			notifiers = append(notifiers, notifier)
+		}
+	}
+	sort.Slice(notifiers, func(i, j int) bool {
+		return notifiers[i].Name() < notifiers[j].Name()
+	})
+	return notifiers
+}
+
+// DeliveryResult 单个渠道的发送结果
+type DeliveryResult struct {
+	Channel  string
+	Err      error
+	Duration time.Duration
+}
+
+// SendResult 所有渠道的发送结果汇总
+type SendResult struct {
+	Results []DeliveryResult
+}
+
+// Succeeded 返回发送成功的渠道
+func (r *SendResult) Succeeded() []string {
+	var channels []string
+	for _, res := range r.Results {
+		if res.Err == nil {
+			channels = append(channels, res.Channel)
+		}
+	}
+	return channels
+}
+
+// Failed 返回发送失败的渠道及错误
+func (r *SendResult) Failed() map[string]error {
+	failed := make(map[string]error)
+	for _, res := range r.Results {
+		if res.Err != nil {
+			failed[res.Channel] = res.Err
+		}
+	}
+	return failed
+}
+
+// Err 汇总所有失败渠道的错误, 全部成功时返回 nil
+func (r *SendResult) Err() error {
+	var errs []error
+	for _, res := range r.Results {
+		if res.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", res.Channel, res.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// 已创建的通知渠道, 配置变更时通过 ReloadNotifiers 重建
+var (
+	notifiers      []Notifier
+	notifiersBuilt bool
+	notifierMutex  sync.RWMutex
+)
+
+// ReloadNotifiers 根据新的 webhook 配置重建通知渠道
+func ReloadNotifiers(cfg config.WebhookConfig) {
+	built := BuildNotifiers(cfg)
+	notifierMutex.Lock()
+	defer notifierMutex.Unlock()
+	notifiers = built
+	notifiersBuilt = true
+}
+
+func getNotifiers() ([]Notifier, error) {
+	notifierMutex.RLock()
+	if notifiersBuilt {
+		defer notifierMutex.RUnlock()
+		return notifiers, nil
+	}
+	notifierMutex.RUnlock()
+
+	appConfig, err := config.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if appConfig == nil {
+		return nil, fmt.Errorf("config is nil")
+	}
+	ReloadNotifiers(appConfig.Webhook)
+	notifierMutex.RLock()
+	defer notifierMutex.RUnlock()
+	return notifiers, nil
+}
+
+// Broadcast 并发发送消息到所有已配置的通知渠道, 返回每个渠道的发送结果
+func Broadcast(msg string) (*SendResult, error) {
+	list, err := getNotifiers()
+	if err != nil {
+		return nil, err
+	}
+	return Deliver(list, msg), nil
+}
+
+// Multicast 并发发送消息到指定名称的通知渠道, 指定的渠道均未配置时退化为发送到所有渠道, 避免告警丢失
+func Multicast(channels []string, msg string) (*SendResult, error) {
+	list, err := getNotifiers()
+	if err != nil {
+		return nil, err
+	}
+	var selected []Notifier
+	for _, notifier := range list {
+		if slices.Contains(channels, notifier.Name()) {
+			selected = append(selected, notifier)
+		}
+	}
+	if len(selected) == 0 {
+		pkg.GetLogger().Warn("None of the requested channels is configured, sending to all", "channels", channels)
+		selected = list
+	}
+	return Deliver(selected, msg), nil
+}
+
+// Deliver 并发发送消息到指定通知渠道
+func Deliver(notifiers []Notifier, msg string) *SendResult {
+	result := &SendResult{Results: make([]DeliveryResult, len(notifiers))}
+	var wg sync.WaitGroup
+	for i, notifier := range notifiers {
+		wg.Add(1)
+		go func(i int, n Notifier) {
+			defer wg.Done()
+			start := time.Now()
+			err := n.Send(msg)
+			result.Results[i] = DeliveryResult{Channel: n.Name(), Err: err, Duration: time.Since(start)}
+			metrics.NotifySent.WithLabelValues(n.Name(), metrics.Result(err)).Inc()
+			if err != nil {
+				pkg.GetLogger().Warn("Notifier send failed", "channel", n.Name(), "error", err)
+			}
+		}(i, notifier)
+	}
+	wg.Wait()
+	return result
+}
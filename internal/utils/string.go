@@ -20,3 +20,11 @@ func RemoveDuplicates(strings []string) []string {
 
 	return result
 }
+
+// MaskAddress 地址只保留开头 6 位和结尾 4 位, 过短的地址原样返回
+func MaskAddress(address string) string {
+	if len(address) <= 10 {
+		return address
+	}
+	return address[:6] + "**" + address[len(address)-4:]
+}
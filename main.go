@@ -1,7 +1,12 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/fuxingjun/balance-bot/internal/config"
 	"github.com/fuxingjun/balance-bot/internal/core"
@@ -9,8 +14,11 @@ import (
 	"github.com/fuxingjun/balance-bot/pkg"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 var (
@@ -20,30 +28,77 @@ var (
 
 func main() {
 	fmt.Printf("version: %s, build time: %s\n", version, date)
+	config.SetPath(utils.GetArgs().Config)
+	switch command := utils.GetArgs().Command; command {
+	case "":
+	case "validate":
+		os.Exit(validateConfig(utils.GetArgs().Params))
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n", command)
+		os.Exit(2)
+	}
 	pkg.InitLoggerDefault(utils.GetArgs().Debug)
 	appConfig, err := config.LoadConfig()
 	if err != nil {
 		panic(err)
 	}
 	if appConfig == nil {
-		println("配置文件不存在, 已生成示例文件 config.json, 请根据需要修改后重新运行程序。")
+		println("配置文件不存在, 已生成示例文件", config.Path()+", 请根据需要修改后重新运行程序。")
 		return
 	}
 	println("配置文件加载成功,", "gas检测间隔:", appConfig.Interval, "秒")
 	for _, token := range appConfig.Tokens {
-		// 地址只显示开始和结尾, 浮点数显示4位小数
-		println("代币地址:", token.Address[:6], "...", token.Address[len(token.Address)-4:], "链ID:", token.ChainId, "名称:", token.Name, "最小值:", fmt.Sprintf("%.4f", token.Min), "最大值:", fmt.Sprintf("%.4f", token.Max))
+		// 地址只显示开始和结尾, 阈值按配置原样显示
+		maxText := "不限"
+		if _, ok := token.MaxAmount(); ok {
+			maxText = string(token.Max)
+		}
+		println("代币地址:", utils.MaskAddress(token.Address), "链ID:", token.ChainId, "名称:", token.Name, "最小值:", string(token.Min), "最大值:", maxText)
+	}
+	// 恢复上次运行的监控状态
+	if err := core.RestoreState(); err != nil {
+		panic(err)
 	}
 	core.CheckBalance()
+	// 启动 RPC 节点健康探测
+	go core.StartRPCHealthProbe()
+
+	// 监听配置文件变更, 校验通过后增量应用
+	config.OnChange(core.ApplyConfigChange)
+	go config.Watch(2 * time.Second)
 
-	// 启动后台指数监控任务
+	// 启动后台指数监控任务, 开关支持热加载
+	go core.StartIndexMonitor()
 	if appConfig.IndexComponentMonitor {
-		go core.StartIndexMonitor()
 		println("合约指数成份监控已启用。")
 	} else {
 		println("合约指数成份监控未启用。")
 	}
 
+	// 启动后台合约状态监控任务, 开关支持热加载
+	go core.StartContractStatusMonitor()
+	if appConfig.ContractStatusMonitor {
+		println("合约状态监控已启用。")
+	} else {
+		println("合约状态监控未启用。")
+	}
+
+	// 启动后台持仓量监控任务, 开关支持热加载
+	go core.StartOpenInterestMonitor()
+	if appConfig.OpenInterestMonitor.Enabled {
+		println("持仓量监控已启用, 窗口跌幅阈值:", fmt.Sprintf("%.2f%%", appConfig.OpenInterestMonitor.DropThreshold*100))
+	} else {
+		println("持仓量监控未启用。")
+	}
+
+	// 启动后台价差监控任务, 开关支持热加载
+	go core.StartSpreadMonitor()
+	if appConfig.SpreadMonitor.Enabled {
+		println("价差监控已启用, 阈值:", fmt.Sprintf("%.2f%%", appConfig.SpreadMonitor.Threshold*100), "价格:", appConfig.SpreadMonitor.PriceType)
+	} else {
+		println("价差监控未启用。")
+	}
+
 	// 健康检测信息
 	println("健康检测间隔:", appConfig.HealthCheck.Interval, "告警次数:", appConfig.HealthCheck.WarnCount)
 
@@ -60,25 +115,80 @@ func main() {
 	// 启动一个http服务
 	args := utils.GetArgs()
 	app := fiber.New()
+	// handler panic 时返回 500, 避免单个请求导致进程退出
+	app.Use(recover.New())
 	if args.Debug {
 		app.Use(logger.New())
 	}
 
-	// 跨域
-	app.Use(cors.New())
-	// 或者扩展你的配置以进行自定义
+	// 跨域, 允许的来源见配置 api.corsOrigins
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: "https://gofiber.io, https://taoli.tools",
-		AllowHeaders: "Origin, Content-Type, Accept",
+		AllowOriginsFunc: core.AllowOrigin,
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Timestamp, X-Signature",
 	}))
 
-	app.Post("/health", core.HealthCheck)
-	app.Post("/monitor", core.PairsMonitor)
+	if len(appConfig.API.Tokens) == 0 && appConfig.API.HMAC.Secret == "" {
+		println("警告: 未配置 api.tokens 或 api.hmac.secret, HTTP 接口未启用鉴权。")
+	} else if len(appConfig.API.Tokens) == 0 {
+		println("提示: 只配置了 api.hmac.secret, GET 接口同样需要签名, Prometheus 等无法签名的客户端需配置 api.tokens。")
+	}
+
+	app.Post("/health", core.Auth(core.ScopeHealth), core.HealthCheck)
+	app.Delete("/health/:name", core.Auth(core.ScopeHealth), core.DeregisterHealth)
+	app.Post("/health/:name/pause", core.Auth(core.ScopeHealth), core.PauseHealth)
+	app.Post("/health/:name/resume", core.Auth(core.ScopeHealth), core.ResumeHealth)
+	app.Post("/monitor", core.Auth(core.ScopeMonitor), core.PairsMonitor)
+	app.Get("/status/rpc", core.Auth(core.ScopeStatus), core.RPCStatus)
+	app.Get("/status/balances", core.Auth(core.ScopeStatus), core.BalanceStatus)
+	app.Get("/status/health", core.Auth(core.ScopeStatus), core.HealthStatusList)
+	app.Get("/status/health/:name", core.Auth(core.ScopeStatus), core.HealthServiceDetail)
+	app.Get("/status/symbols", core.Auth(core.ScopeStatus), core.SymbolsStatus)
+	app.Get("/status/index", core.Auth(core.ScopeStatus), core.IndexStatus)
+	app.Get("/status/maintenance", core.Auth(core.ScopeStatus), core.MaintenanceStatus)
+	app.Get("/status/ratelimit", core.Auth(core.ScopeStatus), core.RateLimitStatus)
+	app.Get("/metrics", core.Auth(core.ScopeStatus), adaptor.HTTPHandler(promhttp.Handler()))
 
 	addr := fmt.Sprintf("%s:%d", args.Host, args.Port)
 	// 启动服务器在 指定 端口
 	fmt.Printf("Listening on %s\n", addr)
+	// 收到退出信号后停止 http 服务, 关闭状态存储以合并 WAL
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		<-sig
+		if err := app.Shutdown(); err != nil {
+			fmt.Printf("shutdown failed: %v\n", err)
+		}
+	}()
 	if err := app.Listen(addr); err != nil {
 		fmt.Printf("listen failed: %v\n", err)
 	}
+	core.CloseState()
+}
+
+// 校验配置文件, 默认校验 -config 指定的文件, 全部通过返回 0, 否则逐条输出错误并返回 1
+func validateConfig(params []string) int {
+	paths := params
+	if len(paths) == 0 {
+		paths = []string{config.Path()}
+	}
+	code := 0
+	for _, path := range paths {
+		cfg, err := config.ValidateFile(path)
+		if err != nil {
+			code = 1
+			fmt.Fprintf(os.Stderr, "%s: invalid\n", path)
+			var errs config.ValidationErrors
+			if errors.As(err, &errs) {
+				for _, e := range errs {
+					fmt.Fprintf(os.Stderr, "  - %s\n", e.Error())
+				}
+			} else {
+				fmt.Fprintf(os.Stderr, "  - %v\n", err)
+			}
+			continue
+		}
+		fmt.Printf("%s: ok, %d tokens, %d chains\n", path, len(cfg.Tokens), len(cfg.Chains))
+	}
+	return code
 }
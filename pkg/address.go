@@ -0,0 +1,45 @@
+package pkg
+
+import (
+	"encoding/hex"
+	"regexp"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+var hexAddressPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+
+// IsHexAddress 判断是否为 0x 开头的 40 位十六进制地址
+func IsHexAddress(address string) bool {
+	return hexAddressPattern.MatchString(address)
+}
+
+// ToChecksumAddress 转换为 EIP-55 校验和格式的地址
+func ToChecksumAddress(address string) string {
+	lower := strings.ToLower(strings.TrimPrefix(address, "0x"))
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write([]byte(lower))
+	digest := hex.EncodeToString(hash.Sum(nil))
+
+	result := []byte(lower)
+	for i, ch := range result {
+		// 哈希对应位 >= 8 时字母大写
+		if ch >= 'a' && ch <= 'f' && digest[i] >= '8' {
+			result[i] = ch - 32
+		}
+	}
+	return "0x" + string(result)
+}
+
+// IsChecksumAddress 校验地址格式; 全小写或全大写视为未带校验和的合法地址, 大小写混合时必须符合 EIP-55
+func IsChecksumAddress(address string) bool {
+	if !IsHexAddress(address) {
+		return false
+	}
+	body := address[2:]
+	if body == strings.ToLower(body) || body == strings.ToUpper(body) {
+		return true
+	}
+	return address == ToChecksumAddress(address)
+}
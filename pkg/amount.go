@@ -0,0 +1,144 @@
+package pkg
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Amount 定点数金额, 实际值为 Value / 10^Decimals, 全程使用整数运算避免浮点误差
+type Amount struct {
+	Value    *big.Int
+	Decimals uint8
+}
+
+// NewAmount 由最小单位整数与精度构造金额, 比如 wei 与 18
+func NewAmount(value *big.Int, decimals uint8) Amount {
+	if value == nil {
+		value = new(big.Int)
+	}
+	return Amount{Value: new(big.Int).Set(value), Decimals: decimals}
+}
+
+// ParseAmount 解析十进制数字字符串(支持科学计数法), 精度取刚好能精确表示该值的位数
+func ParseAmount(str string) (Amount, error) {
+	str = strings.TrimSpace(str)
+	r, ok := new(big.Rat).SetString(str)
+	if !ok {
+		return Amount{}, fmt.Errorf("invalid decimal: %q", str)
+	}
+	ten := big.NewInt(10)
+	num := new(big.Int).Set(r.Num())
+	denom := r.Denom()
+	// 分母只含因子 2 和 5 时才能用有限位小数表示
+	for decimals := 0; decimals <= 255; decimals++ {
+		if new(big.Int).Mod(num, denom).Sign() == 0 {
+			return Amount{Value: num.Div(num, denom), Decimals: uint8(decimals)}, nil
+		}
+		num.Mul(num, ten)
+	}
+	return Amount{}, fmt.Errorf("decimal has too many fractional digits: %q", str)
+}
+
+func pow10(n uint8) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// Rescale 转换到指定精度, 精度降低时向零截断
+func (a Amount) Rescale(decimals uint8) Amount {
+	value := a.value()
+	switch {
+	case decimals > a.Decimals:
+		value = new(big.Int).Mul(value, pow10(decimals-a.Decimals))
+	case decimals < a.Decimals:
+		value = new(big.Int).Quo(value, pow10(a.Decimals-decimals))
+	default:
+		value = new(big.Int).Set(value)
+	}
+	return Amount{Value: value, Decimals: decimals}
+}
+
+func (a Amount) value() *big.Int {
+	if a.Value == nil {
+		return new(big.Int)
+	}
+	return a.Value
+}
+
+// Cmp 比较两个金额, 精度不同时按较高精度对齐, 返回 -1, 0, 1
+func (a Amount) Cmp(b Amount) int {
+	decimals := max(a.Decimals, b.Decimals)
+	return a.Rescale(decimals).Value.Cmp(b.Rescale(decimals).Value)
+}
+
+// Sign 返回金额符号
+func (a Amount) Sign() int {
+	return a.value().Sign()
+}
+
+// IsZero 判断是否为零
+func (a Amount) IsZero() bool {
+	return a.Sign() == 0
+}
+
+// Float64 转换为浮点数, 会损失精度, 仅用于指标展示, 不要用于比较
+func (a Amount) Float64() float64 {
+	f, _ := new(big.Rat).SetFrac(a.value(), pow10(a.Decimals)).Float64()
+	return f
+}
+
+// String 返回精确的十进制字符串, 去掉小数末尾的 0
+func (a Amount) String() string {
+	return a.Format(int(a.Decimals))
+}
+
+// Format 保留 prec 位小数(向零截断)并去掉末尾的 0
+func (a Amount) Format(prec int) string {
+	if prec < 0 {
+		prec = 0
+	}
+	if prec > int(a.Decimals) {
+		prec = int(a.Decimals)
+	}
+	truncated := a.Rescale(uint8(prec))
+	value := truncated.Value
+	sign := ""
+	if value.Sign() < 0 {
+		sign = "-"
+		value = new(big.Int).Abs(value)
+	}
+	digits := value.String()
+	if prec == 0 {
+		return sign + digits
+	}
+	if len(digits) <= prec {
+		digits = strings.Repeat("0", prec-len(digits)+1) + digits
+	}
+	intPart := digits[:len(digits)-prec]
+	fracPart := strings.TrimRight(digits[len(digits)-prec:], "0")
+	if fracPart == "" {
+		return sign + intPart
+	}
+	return sign + intPart + "." + fracPart
+}
+
+// FormatPretty 同 Format, 整数部分每三位添加千分位逗号, 用于通知展示
+func (a Amount) FormatPretty(prec int) string {
+	str := a.Format(prec)
+	sign := ""
+	if strings.HasPrefix(str, "-") {
+		sign, str = "-", str[1:]
+	}
+	intPart, fracPart, hasFrac := strings.Cut(str, ".")
+	var b strings.Builder
+	for i, ch := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(ch)
+	}
+	if hasFrac {
+		return sign + b.String() + "." + fracPart
+	}
+	return sign + b.String()
+}
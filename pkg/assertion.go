@@ -0,0 +1,207 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// --- JSON 断言: JSONPath 子集 + 比较运算 ---
+
+// JSONPath JSONPath 子集, 支持 $、.key、[n] 与 ["key"]
+type JSONPath struct {
+	raw   string
+	steps []any // string 为对象 key, int 为数组下标
+}
+
+var jsonPathStep = regexp.MustCompile(`^(?:\.([A-Za-z_][A-Za-z0-9_-]*)|\[([0-9]+)\]|\["((?:[^"\\]|\\.)*)"\])`)
+
+// ParseJSONPath 解析 JSONPath, 必须以 $ 开头
+func ParseJSONPath(path string) (JSONPath, error) {
+	if !strings.HasPrefix(path, "$") {
+		return JSONPath{}, fmt.Errorf("json path must start with $: %q", path)
+	}
+	result := JSONPath{raw: path}
+	rest := path[1:]
+	for rest != "" {
+		match := jsonPathStep.FindStringSubmatch(rest)
+		if match == nil {
+			return JSONPath{}, fmt.Errorf("invalid json path %q near %q", path, rest)
+		}
+		switch {
+		case match[1] != "":
+			result.steps = append(result.steps, match[1])
+		case match[2] != "":
+			index, err := strconv.Atoi(match[2])
+			if err != nil {
+				return JSONPath{}, fmt.Errorf("invalid index in json path %q: %w", path, err)
+			}
+			result.steps = append(result.steps, index)
+		default:
+			key, err := strconv.Unquote(`"` + match[3] + `"`)
+			if err != nil {
+				return JSONPath{}, fmt.Errorf("invalid key in json path %q: %w", path, err)
+			}
+			result.steps = append(result.steps, key)
+		}
+		rest = rest[len(match[0]):]
+	}
+	return result, nil
+}
+
+func (p JSONPath) String() string {
+	return p.raw
+}
+
+// Lookup 在 DecodeJSON 解析出的文档中查找值, 不存在时返回 false
+func (p JSONPath) Lookup(doc any) (any, bool) {
+	current := doc
+	for _, step := range p.steps {
+		switch key := step.(type) {
+		case string:
+			obj, ok := current.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if current, ok = obj[key]; !ok {
+				return nil, false
+			}
+		case int:
+			list, ok := current.([]any)
+			if !ok || key >= len(list) {
+				return nil, false
+			}
+			current = list[key]
+		}
+	}
+	return current, true
+}
+
+// DecodeJSON 解析 JSON 文档, 数字保留为 json.Number 以便精确比较
+func DecodeJSON(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Assertion 对 JSON 文档的断言, 形如 `$.queue.depth < 1000`, 比较值为 JSON 字面量
+type Assertion struct {
+	Expr  string
+	Path  JSONPath
+	Op    string
+	Value any
+}
+
+var assertionPattern = regexp.MustCompile(`^\s*(\$\S*?)\s*(==|!=|<=|>=|<|>)\s*(.+?)\s*$`)
+
+// ParseAssertion 解析断言表达式
+func ParseAssertion(expr string) (*Assertion, error) {
+	match := assertionPattern.FindStringSubmatch(expr)
+	if match == nil {
+		return nil, fmt.Errorf("invalid assertion %q, expected `<json path> <op> <value>`", expr)
+	}
+	path, err := ParseJSONPath(match[1])
+	if err != nil {
+		return nil, err
+	}
+	value, err := DecodeJSON([]byte(match[3]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid value in assertion %q, expected a JSON literal: %w", expr, err)
+	}
+	switch value.(type) {
+	case map[string]any, []any:
+		return nil, fmt.Errorf("invalid value in assertion %q, objects and arrays are not comparable", expr)
+	}
+	op := match[2]
+	if _, isNumber := value.(json.Number); !isNumber && op != "==" && op != "!=" {
+		if _, isString := value.(string); !isString {
+			return nil, fmt.Errorf("operator %s in assertion %q requires a number or string", op, expr)
+		}
+	}
+	return &Assertion{Expr: strings.TrimSpace(expr), Path: path, Op: op, Value: value}, nil
+}
+
+// Check 检查文档是否满足断言, 不满足时返回原因
+func (a *Assertion) Check(doc any) error {
+	actual, ok := a.Path.Lookup(doc)
+	if !ok {
+		return fmt.Errorf("%s is missing", a.Path)
+	}
+	cmp, comparable := compareJSON(actual, a.Value)
+	if !comparable {
+		if a.Op == "!=" {
+			return nil
+		}
+		return fmt.Errorf("%s is %s, not comparable with %s", a.Path, formatJSON(actual), formatJSON(a.Value))
+	}
+	var pass bool
+	switch a.Op {
+	case "==":
+		pass = cmp == 0
+	case "!=":
+		pass = cmp != 0
+	case "<":
+		pass = cmp < 0
+	case "<=":
+		pass = cmp <= 0
+	case ">":
+		pass = cmp > 0
+	case ">=":
+		pass = cmp >= 0
+	}
+	if !pass {
+		return fmt.Errorf("%s is %s", a.Path, formatJSON(actual))
+	}
+	return nil
+}
+
+// 比较两个 JSON 值, 只有同类型的数字、字符串、布尔与 null 可比较; 布尔与 null 只区分相等与否
+func compareJSON(a, b any) (int, bool) {
+	switch x := a.(type) {
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return 0, false
+		}
+		rx, okx := new(big.Rat).SetString(x.String())
+		ry, oky := new(big.Rat).SetString(y.String())
+		if !okx || !oky {
+			return 0, false
+		}
+		return rx.Cmp(ry), true
+	case string:
+		y, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(x, y), true
+	case bool:
+		y, ok := b.(bool)
+		if !ok {
+			return 0, false
+		}
+		if x == y {
+			return 0, true
+		}
+		return 1, true
+	case nil:
+		return 0, b == nil
+	}
+	return 0, false
+}
+
+func formatJSON(value any) string {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(raw)
+}
@@ -1,10 +1,38 @@
 package pkg
 
 import (
+	"encoding/json"
 	"sync"
 	"time"
 )
 
+// CacheDecoder 将持久化的 JSON 还原为缓存值
+type CacheDecoder func(key string, raw json.RawMessage) (any, error)
+
+// cachePersistence 缓存绑定的持久化存储
+type cachePersistence struct {
+	store  Store
+	bucket string
+}
+
+func (p *cachePersistence) put(key string, value any) {
+	if p == nil {
+		return
+	}
+	if err := p.store.Put(p.bucket, key, value); err != nil {
+		GetLogger().Warn("Failed to persist cache item", "bucket", p.bucket, "key", key, "error", err)
+	}
+}
+
+func (p *cachePersistence) delete(key string) {
+	if p == nil {
+		return
+	}
+	if err := p.store.Delete(p.bucket, key); err != nil {
+		GetLogger().Warn("Failed to delete persisted cache item", "bucket", p.bucket, "key", key, "error", err)
+	}
+}
+
 // TTLCacheItem 缓存条目
 type TTLCacheItem struct {
 	value      any
@@ -18,9 +46,46 @@ func (item *TTLCacheItem) IsExpired() bool {
 
 // TTLCache 主结构
 type TTLCache struct {
-	items    map[string]*TTLCacheItem
-	duration time.Duration
-	mutex    sync.RWMutex
+	items       map[string]*TTLCacheItem
+	duration    time.Duration
+	mutex       sync.RWMutex
+	persistence *cachePersistence
+	lastSweep   time.Time // SetIfAbsent 上次清理过期项的时间
+}
+
+// ttlCacheRecord TTLCache 条目的持久化格式
+type ttlCacheRecord struct {
+	Value      any       `json:"value"`
+	ExpireTime time.Time `json:"expireTime"`
+}
+
+// Persist 绑定持久化存储: 先从 bucket 恢复未过期的条目, 之后的写入与删除同步到存储
+func (c *TTLCache) Persist(store Store, bucket string, decode CacheDecoder) error {
+	records, err := store.Load(bucket)
+	if err != nil {
+		return err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	now := time.Now()
+	for key, raw := range records {
+		var record struct {
+			Value      json.RawMessage `json:"value"`
+			ExpireTime time.Time       `json:"expireTime"`
+		}
+		if err := json.Unmarshal(raw, &record); err != nil || now.After(record.ExpireTime) {
+			store.Delete(bucket, key)
+			continue
+		}
+		value, err := decode(key, record.Value)
+		if err != nil {
+			GetLogger().Warn("Failed to restore cache item", "bucket", bucket, "key", key, "error", err)
+			continue
+		}
+		c.items[key] = &TTLCacheItem{value: value, expireTime: record.ExpireTime}
+	}
+	c.persistence = &cachePersistence{store: store, bucket: bucket}
+	return nil
 }
 
 // NewTTLCache 创建新缓存, duration 为 TTL
@@ -40,6 +105,35 @@ func (c *TTLCache) Set(key string, value any) {
 		value:      value,
 		expireTime: time.Now().Add(c.duration),
 	}
+	c.persistence.put(key, ttlCacheRecord{Value: value, ExpireTime: c.items[key].expireTime})
+}
+
+// SetIfAbsent 键不存在或已过期时以指定 TTL 写入并返回 true, 否则不写入并返回 false
+// 判断与写入在同一把锁内完成, 并发调用时只有一个能写入成功
+// 每个默认 TTL 周期顺带清理一次过期项, 避免只写不读的缓存无限增长
+func (c *TTLCache) SetIfAbsent(key string, value any, ttl time.Duration) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastSweep) >= c.duration {
+		for k, v := range c.items {
+			if now.After(v.expireTime) {
+				delete(c.items, k)
+				c.persistence.delete(k)
+			}
+		}
+		c.lastSweep = now
+	}
+	if item, exists := c.items[key]; exists && !item.IsExpired() {
+		return false
+	}
+	c.items[key] = &TTLCacheItem{
+		value:      value,
+		expireTime: time.Now().Add(ttl),
+	}
+	c.persistence.put(key, ttlCacheRecord{Value: value, ExpireTime: c.items[key].expireTime})
+	return true
 }
 
 // Get 获取缓存项, 若过期则返回 nil, false
@@ -59,6 +153,7 @@ func (c *TTLCache) Delete(key string) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 	delete(c.items, key)
+	c.persistence.delete(key)
 }
 
 // Clean 清理所有过期项（可选调用）
@@ -70,14 +165,16 @@ func (c *TTLCache) Clean() {
 	for k, v := range c.items {
 		if now.After(v.expireTime) {
 			delete(c.items, k)
+			c.persistence.delete(k)
 		}
 	}
 }
 
 // SimpleCache 一个不过期的并发安全缓存
 type SimpleCache struct {
-	items map[string]any
-	mu    sync.RWMutex
+	items       map[string]any
+	mu          sync.RWMutex
+	persistence *cachePersistence
 }
 
 // NewSimpleCache 创建一个永不过期的缓存
@@ -90,11 +187,39 @@ func NewSimpleCache(init *map[string]any) *SimpleCache {
 	}
 }
 
+// Persist 绑定持久化存储: 先从 bucket 恢复所有条目, 之后的写入与删除同步到存储
+func (c *SimpleCache) Persist(store Store, bucket string, decode CacheDecoder) error {
+	records, err := store.Load(bucket)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, raw := range records {
+		value, err := decode(key, raw)
+		if err != nil {
+			GetLogger().Warn("Failed to restore cache item", "bucket", bucket, "key", key, "error", err)
+			continue
+		}
+		c.items[key] = value
+	}
+	c.persistence = &cachePersistence{store: store, bucket: bucket}
+	return nil
+}
+
 // Set 添加值（永不过期）
 func (c *SimpleCache) Set(key string, value any) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.items[key] = value
+	c.persistence.put(key, value)
+}
+
+// SetWithoutPersist 只更新内存中的值, 不写入存储; 用于只需在关键字段变化时持久化的高频更新
+func (c *SimpleCache) SetWithoutPersist(key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[key] = value
 }
 
 // Get 获取值，第二个返回值表示是否存在
@@ -110,12 +235,16 @@ func (c *SimpleCache) Delete(key string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	delete(c.items, key)
+	c.persistence.delete(key)
 }
 
 // Clear 清空所有键
 func (c *SimpleCache) Clear() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	for k := range c.items {
+		c.persistence.delete(k)
+	}
 	c.items = make(map[string]any)
 }
 
@@ -138,3 +267,14 @@ func (c *SimpleCache) GetAllKeys() map[string][]string {
 	}
 	return result
 }
+
+// Items 返回所有条目的副本
+func (c *SimpleCache) Items() map[string]any {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	result := make(map[string]any, len(c.items))
+	for k, v := range c.items {
+		result[k] = v
+	}
+	return result
+}
@@ -4,12 +4,47 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/valyala/fasthttp"
 )
 
+// HTTPStatusError 非 2xx 响应, 保留状态码与 Retry-After 供调用方退避
+type HTTPStatusError struct {
+	StatusCode int
+	RetryAfter time.Duration // 响应头 Retry-After, 未返回时为 0
+	Body       string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("request failed with status code: %d, response: %s", e.StatusCode, e.Body)
+}
+
+func newHTTPStatusError(resp *fasthttp.Response) *HTTPStatusError {
+	return &HTTPStatusError{
+		StatusCode: resp.StatusCode(),
+		RetryAfter: parseRetryAfter(string(resp.Header.Peek("Retry-After"))),
+		Body:       string(resp.Body()),
+	}
+}
+
+// Retry-After 可以是秒数或 HTTP 日期
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return 0
+}
+
 type HTTPClient struct {
 	client *fasthttp.Client
 }
@@ -58,7 +93,7 @@ func (a *HTTPClient) SendGetRequest(url string, params map[string]any, headers m
 	// 检查响应状态码
 	statusCode := resp.StatusCode()
 	if statusCode < 200 || statusCode >= 300 {
-		return nil, fmt.Errorf("request failed with status code: %d, response: %s", statusCode, resp.Body())
+		return nil, newHTTPStatusError(resp)
 	}
 
 	return bytes.Clone(resp.Body()), nil
@@ -140,7 +175,7 @@ func (a *HTTPClient) SendPostRequest(url string, payload any, params map[string]
 	// 检查响应状态码
 	statusCode := resp.StatusCode()
 	if statusCode < 200 || statusCode >= 300 {
-		return nil, fmt.Errorf("request failed with status code: %d, response: %s", statusCode, resp.Body())
+		return nil, newHTTPStatusError(resp)
 	}
 
 	return bytes.Clone(resp.Body()), nil
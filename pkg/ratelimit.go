@@ -0,0 +1,123 @@
+package pkg
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimiter 令牌桶限速器, 并发安全, 令牌不足时 Wait 阻塞等待
+// 收到 429/418 后调用 Backoff 暂停, 暂停期间 Wait 直接返回错误, 避免长时间阻塞调用方
+type RateLimiter struct {
+	mu     sync.Mutex
+	limit  int           // 窗口内允许的权重
+	window time.Duration // 窗口长度
+	rate   float64       // 每秒补充的令牌数
+	tokens float64
+	last   time.Time
+
+	blockedUntil time.Time
+	requests     int64         // 通过的请求数
+	weight       int64         // 通过的请求权重合计
+	waited       time.Duration // 因令牌不足累计等待的时长
+	throttled    int64         // 收到限流响应的次数
+	rejected     int64         // 暂停期间被拒绝的请求数
+}
+
+// RateLimiterStats 限速器状态快照
+type RateLimiterStats struct {
+	Limit        int     `json:"limit"`
+	Window       string  `json:"window"`
+	Available    float64 `json:"available"`              // 当前可用令牌
+	Requests     int64   `json:"requests"`               // 通过的请求数
+	Weight       int64   `json:"weight"`                 // 通过的请求权重合计
+	WaitedMs     int64   `json:"waitedMs"`               // 因令牌不足累计等待的毫秒数
+	Throttled    int64   `json:"throttled"`              // 收到 429/418 的次数
+	Rejected     int64   `json:"rejected"`               // 暂停期间被拒绝的请求数
+	BlockedUntil string  `json:"blockedUntil,omitempty"` // 暂停截止时间, 未暂停时为空
+}
+
+// NewRateLimiter 创建每 window 时间内最多 limit 个令牌的限速器, 初始为满桶
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:  limit,
+		window: window,
+		rate:   float64(limit) / window.Seconds(),
+		tokens: float64(limit),
+		last:   time.Now(),
+	}
+}
+
+// 按经过的时间补充令牌, 调用方需持有锁
+func (l *RateLimiter) refill(now time.Time) {
+	l.tokens = min(float64(l.limit), l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+}
+
+// Wait 取走 weight 个令牌, 不足时等待补充; weight 超过桶容量时按桶容量计算
+// 处于 Backoff 暂停期间时不取令牌, 直接返回错误
+func (l *RateLimiter) Wait(weight int) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	if now.Before(l.blockedUntil) {
+		l.rejected++
+		until := l.blockedUntil
+		l.mu.Unlock()
+		return fmt.Errorf("rate limited until %s", until.Format(time.RFC3339))
+	}
+	l.refill(now)
+	l.tokens -= float64(min(weight, l.limit))
+	l.requests++
+	l.weight += int64(weight)
+	// 令牌可以为负, 表示之后的请求需要排队等待的量
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+		l.waited += delay
+	}
+	l.mu.Unlock()
+	time.Sleep(delay)
+	return nil
+}
+
+// Backoff 收到限流响应后暂停 d, 并清空令牌, 恢复后从空桶开始补充
+func (l *RateLimiter) Backoff(d time.Duration) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.throttled++
+	if until := now.Add(d); until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+	l.tokens = 0
+	l.last = l.blockedUntil
+}
+
+// Stats 返回限速器状态快照
+func (l *RateLimiter) Stats() RateLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	stats := RateLimiterStats{
+		Limit:     l.limit,
+		Window:    l.window.String(),
+		Requests:  l.requests,
+		Weight:    l.weight,
+		WaitedMs:  l.waited.Milliseconds(),
+		Throttled: l.throttled,
+		Rejected:  l.rejected,
+	}
+	if now.Before(l.blockedUntil) {
+		stats.BlockedUntil = l.blockedUntil.Format(time.RFC3339)
+	} else {
+		l.refill(now)
+		stats.Available = l.tokens
+	}
+	return stats
+}
@@ -0,0 +1,230 @@
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Store 持久化存储接口, 数据按 bucket 分组, 值序列化为 JSON
+type Store interface {
+	// Load 读取 bucket 下的所有数据
+	Load(bucket string) (map[string]json.RawMessage, error)
+	// Put 写入或覆盖一个键
+	Put(bucket, key string, value any) error
+	// Delete 删除一个键
+	Delete(bucket, key string) error
+	// Close 关闭存储, 关闭前会整理数据
+	Close() error
+}
+
+// walEntry 预写日志条目
+type walEntry struct {
+	Op     string          `json:"op"` // put / del
+	Bucket string          `json:"bucket"`
+	Key    string          `json:"key"`
+	Value  json.RawMessage `json:"value,omitempty"`
+}
+
+// FileStore JSON 快照 + 预写日志(WAL)的文件存储
+// 每次写入先追加到 WAL, 每隔 syncInterval 统一落盘一次; WAL 条目数超过阈值后合并到快照并清空 WAL
+// 与当前值相同的写入和不存在的键的删除直接忽略, 不写 WAL
+type FileStore struct {
+	dir        string
+	data       map[string]map[string]json.RawMessage
+	wal        *os.File
+	walEntries int
+	compactAt  int
+	dirty      bool // WAL 有未落盘的写入
+	done       chan struct{}
+	mu         sync.Mutex
+}
+
+const (
+	storeSnapshotFile = "snapshot.json"
+	storeWALFile      = "wal.log"
+	// WAL 落盘间隔, 进程崩溃不丢数据, 系统崩溃最多丢失这段时间内的写入
+	storeSyncInterval = time.Second
+)
+
+// NewFileStore 打开目录下的存储, 加载快照并重放 WAL
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	s := &FileStore{
+		dir:       dir,
+		data:      make(map[string]map[string]json.RawMessage),
+		compactAt: 1000,
+		done:      make(chan struct{}),
+	}
+	// 1. 加载快照
+	snapshot, err := os.ReadFile(filepath.Join(dir, storeSnapshotFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(snapshot) > 0 {
+		if err := json.Unmarshal(snapshot, &s.data); err != nil {
+			return nil, fmt.Errorf("failed to parse snapshot: %w", err)
+		}
+	}
+	// 2. 重放 WAL, 最后一行可能因崩溃写入不完整, 直接忽略
+	if f, err := os.Open(filepath.Join(dir, storeWALFile)); err == nil {
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			var entry walEntry
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				GetLogger().Warn("Skipping corrupted wal entry", "error", err)
+				continue
+			}
+			s.apply(entry)
+		}
+		f.Close()
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	// 3. 合并为新快照, 重新开始 WAL
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+	go s.syncLoop()
+	return s, nil
+}
+
+// 定时将 WAL 落盘, 合并多次写入的 fsync
+func (s *FileStore) syncLoop() {
+	ticker := time.NewTicker(storeSyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			if s.dirty && s.wal != nil {
+				if err := s.wal.Sync(); err != nil {
+					GetLogger().Warn("Failed to sync wal", "error", err)
+				} else {
+					s.dirty = false
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+func (s *FileStore) apply(entry walEntry) {
+	bucket, exists := s.data[entry.Bucket]
+	if !exists {
+		bucket = make(map[string]json.RawMessage)
+		s.data[entry.Bucket] = bucket
+	}
+	switch entry.Op {
+	case "put":
+		bucket[entry.Key] = entry.Value
+	case "del":
+		delete(bucket, entry.Key)
+	}
+}
+
+// Load 读取 bucket 下的所有数据
+func (s *FileStore) Load(bucket string) (map[string]json.RawMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make(map[string]json.RawMessage, len(s.data[bucket]))
+	for k, v := range s.data[bucket] {
+		result[k] = v
+	}
+	return result, nil
+}
+
+// Put 写入或覆盖一个键
+func (s *FileStore) Put(bucket, key string, value any) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return s.write(walEntry{Op: "put", Bucket: bucket, Key: key, Value: raw})
+}
+
+// Delete 删除一个键
+func (s *FileStore) Delete(bucket, key string) error {
+	return s.write(walEntry{Op: "del", Bucket: bucket, Key: key})
+}
+
+func (s *FileStore) write(entry walEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.wal == nil {
+		return fmt.Errorf("store is closed")
+	}
+	// 值未变化或键不存在时无需写入
+	current, exists := s.data[entry.Bucket][entry.Key]
+	if entry.Op == "put" && exists && bytes.Equal(current, entry.Value) || entry.Op == "del" && !exists {
+		return nil
+	}
+	if _, err := s.wal.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	s.dirty = true
+	s.apply(entry)
+	s.walEntries++
+	if s.walEntries >= s.compactAt {
+		return s.compact()
+	}
+	return nil
+}
+
+// compact 将内存数据写为快照并清空 WAL, 调用方需持有锁
+func (s *FileStore) compact() error {
+	raw, err := json.Marshal(s.data)
+	if err != nil {
+		return err
+	}
+	// 先写临时文件再重命名, 保证快照完整
+	tmp := filepath.Join(s.dir, storeSnapshotFile+".tmp")
+	if err := os.WriteFile(tmp, raw, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(s.dir, storeSnapshotFile)); err != nil {
+		return err
+	}
+	if s.wal != nil {
+		s.wal.Close()
+	}
+	wal, err := os.OpenFile(filepath.Join(s.dir, storeWALFile), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		s.wal = nil
+		return err
+	}
+	s.wal = wal
+	s.walEntries = 0
+	s.dirty = false
+	return nil
+}
+
+// Close 合并快照并关闭 WAL
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.wal == nil {
+		return nil
+	}
+	close(s.done)
+	err := s.compact()
+	if s.wal != nil {
+		s.wal.Close()
+		s.wal = nil
+	}
+	return err
+}